@@ -0,0 +1,215 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// Aggregation is an aggregate expression selected by Aggregate, e.g.
+// SUM(amount) AS total. Build it with Sum, Avg, Min, Max or Count.
+type Aggregation struct {
+	fn       string
+	column   string
+	alias    string
+	distinct bool
+}
+
+// Sum returns SUM(column).
+func Sum(column string) Aggregation { return Aggregation{fn: "SUM", column: column} }
+
+// Avg returns AVG(column).
+func Avg(column string) Aggregation { return Aggregation{fn: "AVG", column: column} }
+
+// Min returns MIN(column).
+func Min(column string) Aggregation { return Aggregation{fn: "MIN", column: column} }
+
+// Max returns MAX(column).
+func Max(column string) Aggregation { return Aggregation{fn: "MAX", column: column} }
+
+// Count returns COUNT(column); pass "*" to count rows.
+func Count(column string) Aggregation { return Aggregation{fn: "COUNT", column: column} }
+
+// As names the result column. Without As, the alias is the lower-cased
+// function and column, e.g. "sum_amount", or "count" for COUNT(*).
+func (a Aggregation) As(alias string) Aggregation {
+	a.alias = alias
+	return a
+}
+
+// Distinct aggregates distinct values only, e.g. COUNT(DISTINCT user_id).
+func (a Aggregation) Distinct() Aggregation {
+	a.distinct = true
+	return a
+}
+
+// Expr returns the unquoted SQL expression, e.g. "SUM(amount)", for use in
+// a WithHaving predicate (PostgreSQL does not accept aliases in HAVING).
+func (a Aggregation) Expr() string {
+	return a.render(a.column)
+}
+
+func (a Aggregation) render(column string) string {
+	if a.distinct {
+		column = "DISTINCT " + column
+	}
+	return a.fn + "(" + column + ")"
+}
+
+func (a Aggregation) name() string {
+	if a.alias != "" {
+		return a.alias
+	}
+	if a.column == "*" {
+		return strings.ToLower(a.fn)
+	}
+	col := a.column
+	if idx := strings.LastIndexByte(col, '.'); idx >= 0 {
+		col = col[idx+1:]
+	}
+	return strings.ToLower(a.fn) + "_" + col
+}
+
+// WithGroupBy sets the GROUP BY columns of Aggregate. Each must be a column
+// of the entity (see ErrUnknownColumn).
+func WithGroupBy(columns ...string) FindOption {
+	return func(c *findConfig) { c.groupBy = append(c.groupBy, columns...) }
+}
+
+// WithHaving sets the HAVING predicate of Aggregate.
+//
+//	curd.WithHaving(curd.Gt(curd.Sum("amount").Expr(), 100))
+func WithHaving(p Predicate) FindOption {
+	return func(c *findConfig) { c.having = p }
+}
+
+// WithAggregates adds aggregate expressions to Aggregate's select list.
+func WithAggregates(aggs ...Aggregation) FindOption {
+	return func(c *findConfig) { c.aggregates = append(c.aggregates, aggs...) }
+}
+
+// Aggregate runs a GROUP BY query over T's table and scans each group into
+// R. The select list follows R's fields: each mapped column of R must be a
+// WithGroupBy column or the alias of a WithAggregates expression.
+//
+// WithWhere, WithJoins, WithTrashed, WithOrder (on group columns or
+// aggregate aliases), WithLimit and WithOffset apply as in Find;
+// soft-deleted rows are excluded by default.
+//
+// Usage:
+//
+//	type statusTotal struct {
+//	    Status string  `json:"status"`
+//	    Total  float64 `json:"total"`
+//	    Orders int64   `json:"orders"`
+//	}
+//	totals, err := curd.Aggregate[statusTotal](ctx, orders,
+//	    curd.WithGroupBy("status"),
+//	    curd.WithAggregates(curd.Sum("amount").As("total"), curd.Count("*").As("orders")),
+//	    curd.WithHaving(curd.Gt(curd.Sum("amount").Expr(), 100)),
+//	    curd.WithOrder(curd.Desc("total")),
+//	)
+func Aggregate[R any, T Table](ctx context.Context, c *Curd[T], opts ...FindOption) ([]R, error) {
+	name := tableName[T]()
+	query, args, err := buildAggregate[R](c, resolveFindConfig(opts))
+	if err != nil {
+		return nil, fmt.Errorf("aggregate %s: %w", name, err)
+	}
+	defer c.logSQL(ctx, query, args...)()
+	rows, err := c.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate %s: %w", name, err)
+	}
+	defer rows.Close()
+	return scanAllWithMapper[R](ctx, rows, c.fm, c.decoder())
+}
+
+// buildAggregate renders the GROUP BY statement described by cfg with the
+// select list laid out in R's field order.
+func buildAggregate[R any, T Table](c *Curd[T], cfg *findConfig) (string, []any, error) {
+	if cfg.lock != nil {
+		return "", nil, fmt.Errorf("WithLock is not supported by Aggregate")
+	}
+	c = c.withJoins(cfg)
+	groupBy, err := c.resolveColumns(cfg.groupBy)
+	if err != nil {
+		return "", nil, err
+	}
+	// Output columns by name: group columns select themselves, aggregates
+	// their expression under an alias.
+	outputs := make(map[string]string, len(groupBy)+len(cfg.aggregates))
+	for _, col := range groupBy {
+		bare := col[strings.LastIndexByte(col, '.')+1:]
+		outputs[bare] = c.quote(col)
+	}
+	aliases := make(columnSet, len(cfg.aggregates))
+	for _, a := range cfg.aggregates {
+		alias := a.name()
+		if !isPlainIdent(alias) || strings.Contains(alias, ".") {
+			return "", nil, fmt.Errorf("invalid aggregate alias %q", alias)
+		}
+		arg := "*"
+		if a.column != "*" {
+			if arg, err = c.columns.resolve(a.column); err != nil {
+				return "", nil, err
+			}
+			arg = c.quote(arg)
+		}
+		outputs[alias] = a.render(arg) + " AS " + c.quote(alias)
+		aliases[alias] = alias
+	}
+
+	var r R
+	var list []string
+	for _, col := range columnsFromType(reflect.TypeOf(r), c.fm) {
+		expr, ok := outputs[col]
+		if !ok {
+			return "", nil, fmt.Errorf("result column %q is neither grouped nor aggregated", col)
+		}
+		list = append(list, expr)
+	}
+	if len(list) == 0 {
+		return "", nil, fmt.Errorf("result type %T has no columns", r)
+	}
+
+	whereClause, args, err := c.buildFindWhere(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(list, ", "), c.fromClause(cfg), whereClause)
+	if len(groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(c.quoteAll(groupBy), ", ")
+	}
+	havingClause, havingArgs, err := buildPredicateAt(cfg.having, c.dialect, len(args)+1)
+	if err != nil {
+		return "", nil, err
+	}
+	if havingClause != "" {
+		query += " HAVING " + havingClause
+		args = append(args, havingArgs...)
+	}
+
+	// ORDER BY may name group columns and aggregate aliases.
+	orders := cfg.orders
+	if len(orders) == 0 && cfg.orderBy != "" {
+		if orders, err = parseOrderBy(cfg.orderBy); err != nil {
+			return "", nil, err
+		}
+	}
+	if len(orders) > 0 {
+		scoped := *c
+		scoped.columns = maps.Clone(c.columns)
+		maps.Copy(scoped.columns, aliases)
+		orderSQL, err := scoped.orderBySQL(orders)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " ORDER BY " + orderSQL
+	}
+
+	var pageClause string
+	pageClause, args = c.limitOffset(args, cfg.limit, cfg.offset)
+	return query + pageClause, args, nil
+}
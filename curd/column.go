@@ -0,0 +1,198 @@
+package curd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// ErrUnknownColumn is returned when an identifier that Curd interpolates
+// into SQL — an update map key, a WithOrder, WithOrderBy or FindAll sort
+// column, a WithColumns entry or Pluck's column — is not a column of the
+// entity. Resolving these against the entity's FieldMapper-derived columns
+// makes it safe to pass user input such as sort parameters.
+var ErrUnknownColumn = errors.New("curd: unknown column")
+
+// columnSet is the column whitelist of an entity type. It maps every mapped
+// column name, and the Go name of the field it comes from, to the column.
+type columnSet map[string]string
+
+// columnSetOf builds the column whitelist of struct type t.
+func columnSetOf(t reflect.Type, fm FieldMapper) columnSet {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	set := columnSet{}
+	if t.Kind() != reflect.Struct {
+		return set
+	}
+	fields := flatFields(t)
+	for _, f := range fields {
+		if col := f.columnName(fm); col != "" {
+			set[col] = col
+		}
+	}
+	// Go field names resolve too, unless they shadow a column name.
+	for _, f := range fields {
+		col := f.columnName(fm)
+		if _, ok := set[f.Name]; col != "" && !ok {
+			set[f.Name] = col
+		}
+	}
+	return set
+}
+
+// resolve returns the column name refers to: a column or Go field name of
+// the entity, optionally qualified with a table name or alias ("t.name").
+// The qualifier is kept; it must be a plain identifier but is not checked
+// against the FROM clause. A column qualified by a joined table (see
+// withJoins) is returned as is.
+func (s columnSet) resolve(name string) (string, error) {
+	if !isPlainIdent(name) {
+		return "", fmt.Errorf("%w %q", ErrUnknownColumn, name)
+	}
+	qualifier, col := "", name
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		qualifier, col = name[:idx+1], name[idx+1:]
+		if _, joined := s[qualifier+"*"]; joined {
+			return name, nil
+		}
+	}
+	resolved, ok := s[col]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownColumn, name)
+	}
+	return qualifier + resolved, nil
+}
+
+// withJoins returns c with the columns of cfg's joined tables allowed when
+// qualified by the table name or alias ("r.label"). Their columns are not
+// known to c, so any column name is accepted under those qualifiers.
+func (c *Curd[T]) withJoins(cfg *findConfig) *Curd[T] {
+	if len(cfg.joins) == 0 {
+		return c
+	}
+	scoped := *c
+	scoped.columns = maps.Clone(c.columns)
+	for _, j := range cfg.joins {
+		for _, q := range joinQualifiers(j.Table) {
+			if isPlainIdent(q) {
+				scoped.columns[q+".*"] = ""
+			}
+		}
+	}
+	return &scoped
+}
+
+// joinQualifiers returns the names a JoinClause table can be referred to
+// by: "role AS r" and "role r" give role and r.
+func joinQualifiers(table string) []string {
+	words := strings.Fields(table)
+	if len(words) == 3 && strings.EqualFold(words[1], "AS") {
+		return []string{words[0], words[2]}
+	}
+	if len(words) > 2 {
+		return nil
+	}
+	return words
+}
+
+// resolveColumns resolves every name in names against c's columns.
+func (c *Curd[T]) resolveColumns(names []string) ([]string, error) {
+	cols := make([]string, len(names))
+	for i, n := range names {
+		col, err := c.columns.resolve(n)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+// resolveUpdates returns updates keyed by resolved column names. Two keys
+// naming the same column (e.g. "Name" and "name") are rejected.
+func (c *Curd[T]) resolveUpdates(updates map[string]any) (map[string]any, error) {
+	resolved := make(map[string]any, len(updates))
+	for name, val := range updates {
+		col, err := c.columns.resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := resolved[col]; dup {
+			return nil, fmt.Errorf("column %q set twice", col)
+		}
+		resolved[col] = val
+	}
+	return resolved, nil
+}
+
+// resolveOrders returns orders with their columns resolved against c's
+// columns.
+func (c *Curd[T]) resolveOrders(orders []Order) ([]Order, error) {
+	resolved := make([]Order, len(orders))
+	for i, o := range orders {
+		col, err := c.columns.resolve(o.Column)
+		if err != nil {
+			return nil, err
+		}
+		o.Column = col
+		resolved[i] = o
+	}
+	return resolved, nil
+}
+
+// parseOrderBy parses a WithOrderBy or FindAll sort string such as
+// "name ASC, created_date DESC NULLS LAST" into typed orders. Each term is a
+// column followed by an optional ASC or DESC and an optional NULLS FIRST or
+// NULLS LAST; expressions are rejected.
+func parseOrderBy(s string) ([]Order, error) {
+	var orders []Order
+	for _, term := range strings.Split(s, ",") {
+		words := strings.Fields(term)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("order by %q: empty term", s)
+		}
+		o := Order{Column: words[0]}
+		rest := words[1:]
+		if len(rest) > 0 {
+			switch strings.ToUpper(rest[0]) {
+			case "ASC":
+				rest = rest[1:]
+			case "DESC":
+				o.Desc = true
+				rest = rest[1:]
+			}
+		}
+		if len(rest) == 2 && strings.EqualFold(rest[0], "NULLS") {
+			switch strings.ToUpper(rest[1]) {
+			case "FIRST":
+				o.Nulls = NullsFirst
+				rest = nil
+			case "LAST":
+				o.Nulls = NullsLast
+				rest = nil
+			}
+		}
+		if len(rest) > 0 {
+			return nil, fmt.Errorf("order by %q: unsupported term %q", s, strings.TrimSpace(term))
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+// orderBySQL resolves orders and renders them as an ORDER BY list.
+func (c *Curd[T]) orderBySQL(orders []Order) (string, error) {
+	orders, err := c.resolveOrders(orders)
+	if err != nil {
+		return "", err
+	}
+	terms := make([]string, len(orders))
+	for i, o := range orders {
+		terms[i] = c.quote(o.Column) + o.direction()
+	}
+	return strings.Join(terms, ", "), nil
+}
@@ -1,6 +1,7 @@
 package curd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -9,21 +10,28 @@ import (
 type Op string
 
 const (
-	OpEq          Op = "="
-	OpNe          Op = "!="
-	OpGt          Op = ">"
-	OpGte         Op = ">="
-	OpLt          Op = "<"
-	OpLte         Op = "<="
-	OpIn          Op = "IN"
-	OpNotIn       Op = "NOT IN"
-	OpLike        Op = "LIKE"
-	OpILike       Op = "ILIKE"
-	OpIsNull      Op = "IS NULL"
-	OpIsNotNull   Op = "IS NOT NULL"
-	OpBetween     Op = "BETWEEN"
-	OpJSONGet     Op = "->>"   // field->>'key' = value
-	OpJSONContains Op = "@>"    // field @> value
+	OpEq           Op = "="
+	OpNe           Op = "!="
+	OpGt           Op = ">"
+	OpGte          Op = ">="
+	OpLt           Op = "<"
+	OpLte          Op = "<="
+	OpIn           Op = "IN"
+	OpNotIn        Op = "NOT IN"
+	OpLike         Op = "LIKE"
+	OpILike        Op = "ILIKE"
+	OpIsNull       Op = "IS NULL"
+	OpIsNotNull    Op = "IS NOT NULL"
+	OpBetween      Op = "BETWEEN"
+	OpJSONGet      Op = "->>" // field->>'key' = value
+	OpJSONContains Op = "@>"  // field @> value
+
+	// PostgreSQL array and JSONPath operators.
+	OpAny            Op = "= ANY" // field = ANY(array)
+	OpOverlaps       Op = "&&"    // array && array
+	OpArrayContains  Op = "@>"    // array @> array
+	OpJSONPathExists Op = "@?"    // jsonb @? jsonpath
+	OpJSONPathMatch  Op = "@@"    // jsonb @@ jsonpath
 )
 
 // Predicate is a function that builds a WHERE clause fragment.
@@ -51,6 +59,7 @@ type ArgBuilder struct {
 	args []any
 	idx  int
 	d    Dialect
+	err  error // first error reported by a Predicate, see fail
 }
 
 // newArgBuilder creates an ArgBuilder for the given dialect, starting at
@@ -82,6 +91,14 @@ func (b *ArgBuilder) ArgsSlice() []any {
 	return b.args
 }
 
+// fail records why a Predicate cannot be rendered. The first error wins;
+// the method building the query returns it instead of running the query.
+func (b *ArgBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
 // --- Constructor functions ---
 
 // Eq returns a Predicate for field = value.
@@ -188,17 +205,139 @@ func Between(field string, lo, hi any) Predicate {
 //
 //	curd.Eq(curd.JSONField("payload", "uuid"), someUUID)
 //	// generates: payload->>'uuid' = $1
+//
+// key is rendered as a string literal with quotes escaped, so it may come
+// from user input; field is not validated.
 func JSONField(field, key string) string {
-	return fmt.Sprintf("%s->>'%s'", field, key)
+	return fmt.Sprintf("%s%s%s", field, OpJSONGet, quoteLiteral(key))
+}
+
+// quoteLiteral renders s as a single-quoted SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
 }
 
 // JSONContains returns a Predicate for field @> value (PostgreSQL JSONB contains).
 func JSONContains(field string, value any) Predicate {
+	return binaryOp(field, OpJSONContains, value)
+}
+
+// Any returns a Predicate for field = ANY(array) (PostgreSQL). array is
+// bound as a single parameter, e.g. a []int64 or []string.
+func Any(field string, array any) Predicate {
+	return func(b *ArgBuilder) string {
+		return fmt.Sprintf("%s %s(%s)", field, OpAny, b.Arg(array))
+	}
+}
+
+// Overlaps returns a Predicate for field && array: the array column shares
+// at least one element with array (PostgreSQL).
+func Overlaps(field string, array any) Predicate {
+	return binaryOp(field, OpOverlaps, array)
+}
+
+// ArrayContains returns a Predicate for field @> array: the array column
+// holds every element of array (PostgreSQL).
+func ArrayContains(field string, array any) Predicate {
+	return binaryOp(field, OpArrayContains, array)
+}
+
+// JSONPathExists returns a Predicate for field @? path: the JSONPath
+// returns at least one item for the jsonb column (PostgreSQL).
+//
+//	curd.JSONPathExists("payload", `$.items[*] ? (@.qty > 10)`)
+func JSONPathExists(field, path string) Predicate {
+	return binaryOp(field, OpJSONPathExists, path)
+}
+
+// JSONPathMatch returns a Predicate for field @@ path: the JSONPath
+// predicate is true for the jsonb column (PostgreSQL).
+//
+//	curd.JSONPathMatch("payload", `$.total > 100`)
+func JSONPathMatch(field, path string) Predicate {
+	return binaryOp(field, OpJSONPathMatch, path)
+}
+
+// binaryOp returns a Predicate for field op value.
+func binaryOp(field string, op Op, value any) Predicate {
 	return func(b *ArgBuilder) string {
-		return fmt.Sprintf("%s @> %s", field, b.Arg(value))
+		return fmt.Sprintf("%s %s %s", field, op, b.Arg(value))
 	}
 }
 
+// ErrRawArgCount is returned by the methods given a Raw predicate whose
+// number of ? placeholders differs from its number of args.
+var ErrRawArgCount = errors.New("curd: Raw placeholder count mismatch")
+
+// Raw returns a Predicate for a hand-written SQL condition. Each ? in sql
+// is replaced with a placeholder for the next arg in the Curd's dialect,
+// so the fragment combines safely with other predicates. Write ?? for a
+// literal question mark (e.g. the jsonb ? operator); ? inside quoted
+// strings and identifiers is left alone. The fragment is parenthesised.
+//
+// The ?? escape is only meaningful with numbered placeholders such as
+// PostgreSQL's $n. Under a dialect whose placeholder is ? itself (MySQL),
+// the unescaped ? reaches the driver as a placeholder, so a bare question
+// mark must not be used outside quotes there.
+//
+// If the number of placeholders and args differ, the method the predicate
+// is passed to fails with ErrRawArgCount.
+//
+// Usage:
+//
+//	curd.Raw("lower(email) = ?", strings.ToLower(email))
+//	curd.Raw("tags ?? ?", "urgent") // tags ? $1
+func Raw(sql string, args ...any) Predicate {
+	parts := splitRawPlaceholders(sql)
+	var err error
+	if len(parts)-1 != len(args) {
+		err = fmt.Errorf("%w: Raw(%q) has %d placeholders but %d args", ErrRawArgCount, sql, len(parts)-1, len(args))
+	}
+	return func(b *ArgBuilder) string {
+		if err != nil {
+			b.fail(err)
+			return "(" + sql + ")"
+		}
+		var sb strings.Builder
+		sb.WriteByte('(')
+		for i, part := range parts {
+			if i > 0 {
+				sb.WriteString(b.Arg(args[i-1]))
+			}
+			sb.WriteString(part)
+		}
+		sb.WriteByte(')')
+		return sb.String()
+	}
+}
+
+// splitRawPlaceholders splits sql around its ? placeholders, unescaping ??
+// and skipping '...' literals and "..." or `...` identifiers.
+func splitRawPlaceholders(sql string) []string {
+	var parts []string
+	var cur strings.Builder
+	var quote byte
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'', ch == '"', ch == '`':
+			quote = ch
+		case ch == '?' && i+1 < len(sql) && sql[i+1] == '?':
+			i++
+		case ch == '?':
+			parts = append(parts, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteByte(ch)
+	}
+	return append(parts, cur.String())
+}
+
 // --- Combinator functions ---
 
 // And returns a Predicate that AND-s its sub-predicates together.
@@ -290,15 +429,25 @@ func MapWhere(m map[string]any) Predicate {
 }
 
 // buildPredicate evaluates a Predicate and returns the WHERE clause SQL
-// fragment and collected arguments. Returns ("", nil) if pred is nil.
-func buildPredicate(pred Predicate, d Dialect) (clause string, args []any) {
+// fragment and collected arguments. Returns ("", nil, nil) if pred is nil,
+// and the error of a Predicate that cannot be rendered (see Raw).
+func buildPredicate(pred Predicate, d Dialect) (clause string, args []any, err error) {
+	return buildPredicateAt(pred, d, 1)
+}
+
+// buildPredicateAt is like buildPredicate but numbers placeholders from
+// startIdx, for fragments that follow other bound arguments.
+func buildPredicateAt(pred Predicate, d Dialect, startIdx int) (clause string, args []any, err error) {
 	if pred == nil {
-		return "", nil
+		return "", nil, nil
 	}
-	b := newArgBuilder(d, 1)
+	b := newArgBuilder(d, startIdx)
 	clause = pred(b)
+	if b.err != nil {
+		return "", nil, b.err
+	}
 	if clause == "" {
-		return "", nil
+		return "", nil, nil
 	}
-	return clause, b.ArgsSlice()
+	return clause, b.ArgsSlice(), nil
 }
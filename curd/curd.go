@@ -22,13 +22,41 @@ type CurdOption func(*curdConfig)
 
 type curdConfig struct {
 	sqlLogEnabled bool
+	copyThreshold int
+	eachKey       []Order
+	softDelete    SoftDeletePolicy
+	timestamps    TimestampPolicy
 }
 
+// defaultCopyThreshold is the batch size from which InsertBatch switches to
+// the Querier's BulkCopier, if it has one.
+const defaultCopyThreshold = 1000
+
+// maxBindParams is the bind-parameter limit of a single statement in both
+// PostgreSQL and MySQL. InsertBatch and the batch upserts split larger
+// batches into chunks.
+const maxBindParams = 65535
+
 // WithSQLLogging enables SQL logging for all operations on this Curd instance.
 func WithSQLLogging() CurdOption {
 	return func(c *curdConfig) { c.sqlLogEnabled = true }
 }
 
+// WithEachKey sets the sort key Each uses for keyset iteration. The key
+// must be unique across rows (append the primary key to make it so).
+// Defaults to the primary key, ascending.
+func WithEachKey(orders ...Order) CurdOption {
+	return func(c *curdConfig) { c.eachKey = orders }
+}
+
+// WithCopyThreshold sets the minimum number of rows for which InsertBatch
+// uses the Querier's BulkCopier (e.g. PostgreSQL COPY) instead of
+// multi-row INSERT statements. n <= 0 disables the COPY path.
+// Defaults to 1000.
+func WithCopyThreshold(n int) CurdOption {
+	return func(c *curdConfig) { c.copyThreshold = n }
+}
+
 // Table is the interface that entity types must implement.
 type Table interface {
 	TableName() string
@@ -38,11 +66,20 @@ type Table interface {
 // All dependencies (Querier, Dialect, FieldMapper, FieldTransformer) are interfaces,
 // enabling maximum decoupling. Create instances via New[T].
 type Curd[T Table] struct {
-	q          Querier
-	fm         FieldMapper
-	dialect    Dialect
-	transforms []FieldTransformer
-	sqlLog     bool
+	q             Querier
+	fm            FieldMapper
+	dialect       Dialect
+	transforms    []FieldTransformer
+	decoders      []FieldDecoder
+	sqlLog        bool
+	copyThreshold int
+	eachKey       []Order
+	pk            []pkField
+	softDelete    *softDeleteColumn
+	sdPolicy      SoftDeletePolicy
+	timestamps    TimestampPolicy
+	version       *versionField
+	columns       columnSet
 }
 
 // New creates a Curd[T] instance. fm can be nil to use the default mapper
@@ -51,23 +88,56 @@ func New[T Table](q Querier, fm FieldMapper, d Dialect, opts ...CurdOption) *Cur
 	if fm == nil {
 		fm = defaultFieldMapper{}
 	}
-	cfg := &curdConfig{}
+	cfg := &curdConfig{
+		copyThreshold: defaultCopyThreshold,
+		softDelete:    DefaultSoftDelete,
+		timestamps:    DefaultTimestamps,
+	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
-	return &Curd[T]{q: q, fm: fm, dialect: d, sqlLog: cfg.sqlLogEnabled}
+	return &Curd[T]{
+		q:             q,
+		fm:            fm,
+		dialect:       d,
+		sqlLog:        cfg.sqlLogEnabled,
+		copyThreshold: cfg.copyThreshold,
+		eachKey:       cfg.eachKey,
+		pk:            primaryKeyFields(reflect.TypeFor[T](), fm),
+		softDelete:    resolveSoftDelete(reflect.TypeFor[T](), fm, cfg.softDelete),
+		sdPolicy:      cfg.softDelete,
+		timestamps:    cfg.timestamps,
+		version:       versionFieldOf(reflect.TypeFor[T](), fm),
+		columns:       columnSetOf(reflect.TypeFor[T](), fm),
+	}
+}
+
+// clone returns a shallow copy of c for the With* derivation methods.
+func (c *Curd[T]) clone() *Curd[T] {
+	cp := *c
+	return &cp
 }
 
 // WithQuerier returns a new Curd that uses the given Querier (e.g. a transaction)
 // while sharing all other configuration. The original Curd is unchanged.
 func (c *Curd[T]) WithQuerier(q Querier) *Curd[T] {
-	return &Curd[T]{q: q, fm: c.fm, dialect: c.dialect, transforms: c.transforms, sqlLog: c.sqlLog}
+	cp := c.clone()
+	cp.q = q
+	return cp
+}
+
+// querier returns the Querier for operations under ctx: the transaction of
+// an enclosing WithTx on c's Querier, if any, and c's Querier otherwise.
+func (c *Curd[T]) querier(ctx context.Context) Querier {
+	return ContextQuerier(ctx, c.q)
 }
 
 // WithSQLLog returns a new Curd with SQL logging enabled or disabled for
 // subsequent operations. This allows per-operation control over logging.
 func (c *Curd[T]) WithSQLLog(enabled bool) *Curd[T] {
-	return &Curd[T]{q: c.q, fm: c.fm, dialect: c.dialect, transforms: c.transforms, sqlLog: enabled}
+	cp := c.clone()
+	cp.sqlLog = enabled
+	return cp
 }
 
 // WithTransformer returns a new Curd that applies the given FieldTransformer
@@ -77,7 +147,33 @@ func (c *Curd[T]) WithTransformer(t FieldTransformer) *Curd[T] {
 	transforms := make([]FieldTransformer, len(c.transforms), len(c.transforms)+1)
 	copy(transforms, c.transforms)
 	transforms = append(transforms, t)
-	return &Curd[T]{q: c.q, fm: c.fm, dialect: c.dialect, transforms: transforms, sqlLog: c.sqlLog}
+	cp := c.clone()
+	cp.transforms = transforms
+	return cp
+}
+
+// WithDecoder returns a new Curd that applies the given FieldDecoder to
+// scanned values in Find, FindAll, Iter and the other read methods, e.g. to
+// unmarshal JSONB columns written through a FieldTransformer. Multiple
+// decoders compose via chaining or ComposeDecoders.
+func (c *Curd[T]) WithDecoder(d FieldDecoder) *Curd[T] {
+	decoders := make([]FieldDecoder, len(c.decoders), len(c.decoders)+1)
+	copy(decoders, c.decoders)
+	decoders = append(decoders, d)
+	cp := c.clone()
+	cp.decoders = decoders
+	return cp
+}
+
+// decoder returns the composed FieldDecoder of c, or nil.
+func (c *Curd[T]) decoder() FieldDecoder {
+	switch len(c.decoders) {
+	case 0:
+		return nil
+	case 1:
+		return c.decoders[0]
+	}
+	return ComposeDecoders(c.decoders...)
 }
 
 // --- Logging ---
@@ -109,12 +205,16 @@ func logSQLGlobal(ctx context.Context, query string, args ...any) func() {
 }
 
 // formatSQL interpolates parameter values into a SQL query string,
-// replacing $1, $2, ... placeholders with formatted values.
+// replacing $1, $2, ... placeholders with formatted values. Queries without
+// numbered placeholders are treated as positional (MySQL-style "?").
 // The result is for logging/display only — never use it to execute queries.
 func formatSQL(query string, args ...any) string {
 	if len(args) == 0 {
 		return query
 	}
+	if !hasNumberedPlaceholder(query) {
+		return formatPositionalSQL(query, args...)
+	}
 	var buf strings.Builder
 	i := 0
 	for i < len(query) {
@@ -141,6 +241,31 @@ func formatSQL(query string, args ...any) string {
 	return buf.String()
 }
 
+// formatPositionalSQL replaces each "?" placeholder in order with the
+// corresponding formatted argument.
+func formatPositionalSQL(query string, args ...any) string {
+	var buf strings.Builder
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' && n < len(args) {
+			buf.WriteString(formatArg(args[n]))
+			n++
+			continue
+		}
+		buf.WriteByte(query[i])
+	}
+	return buf.String()
+}
+
+func hasNumberedPlaceholder(query string) bool {
+	for i := 0; i+1 < len(query); i++ {
+		if query[i] == '$' && isDigit(query[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
 func isDigit(c byte) bool { return c >= '0' && c <= '9' }
 
 // formatArg formats a single argument value for display in SQL log output.
@@ -193,64 +318,140 @@ func tableName[T Table]() string {
 	return zero.TableName()
 }
 
+// quote quotes an identifier using the configured Dialect.
+func (c *Curd[T]) quote(name string) string {
+	return c.dialect.QuoteIdent(name)
+}
+
+// quoteAll quotes every identifier in names, returning a new slice.
+func (c *Curd[T]) quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = c.quote(n)
+	}
+	return quoted
+}
+
+// limitOffset renders the dialect-specific LIMIT/OFFSET clause, appending
+// the bound values to args. Non-positive limit or offset are omitted.
+func (c *Curd[T]) limitOffset(args []any, limit, offset int) (string, []any) {
+	b := &ArgBuilder{args: args, idx: len(args) + 1, d: c.dialect}
+	clause := c.pageClause(b, limit, offset)
+	return clause, b.ArgsSlice()
+}
+
+// pageClause is limitOffset binding the values through b.
+func (c *Curd[T]) pageClause(b *ArgBuilder, limit, offset int) string {
+	var limitPH, offsetPH string
+	if limit > 0 {
+		limitPH = b.Arg(limit)
+	}
+	if offset > 0 {
+		offsetPH = b.Arg(offset)
+	}
+	if limitPH == "" && offsetPH == "" {
+		return ""
+	}
+	return c.dialect.LimitOffset(limitPH, offsetPH)
+}
+
 // buildWhereClause evaluates a Predicate and combines it with the soft-delete
-// filter (deleted_date IS NULL) when the entity has a DeletedDate field.
-// Returns the complete " WHERE ..." clause and collected arguments.
-func (c *Curd[T]) buildWhereClause(where Predicate) (clause string, args []any) {
-	var t T
+// filter (e.g. deleted_date IS NULL) when the entity has the soft-delete
+// field. Returns the complete " WHERE ..." clause and collected arguments,
+// or the error of a Predicate that cannot be rendered.
+func (c *Curd[T]) buildWhereClause(where Predicate) (clause string, args []any, err error) {
+	return c.buildScopedWhere(where, scopeLive)
+}
 
-	userClause, userArgs := buildPredicate(where, c.dialect)
+// buildScopedWhere is buildWhereClause with an explicit soft-delete scope
+// (see WithTrashed and OnlyTrashed).
+func (c *Curd[T]) buildScopedWhere(where Predicate, scope trashedScope) (clause string, args []any, err error) {
+	b := newArgBuilder(c.dialect, 1)
+	clause = c.scopedWhere(b, where, scope)
+	return clause, b.ArgsSlice(), b.err
+}
 
+// scopedWhere is buildScopedWhere binding the predicate's values through b.
+func (c *Curd[T]) scopedWhere(b *ArgBuilder, where Predicate, scope trashedScope) string {
 	var parts []string
-	if userClause != "" {
-		parts = append(parts, userClause)
+	if where != nil {
+		if userClause := where(b); userClause != "" {
+			parts = append(parts, userClause)
+		}
 	}
-	if hasField(t, "DeletedDate") {
-		parts = append(parts, "deleted_date IS NULL")
+	if filter := c.softDeleteFilter(scope); filter != "" {
+		parts = append(parts, filter)
 	}
 
 	if len(parts) == 0 {
-		return "", nil
+		return ""
 	}
-	return " WHERE " + strings.Join(parts, " AND "), userArgs
+	return " WHERE " + strings.Join(parts, " AND ")
+}
+
+// findWhere is scopedWhere for cfg's predicate and soft-delete scope. When
+// cfg joins other tables, the soft-delete column is qualified with T's
+// table, as the joined tables may have a column of the same name.
+func (c *Curd[T]) findWhere(b *ArgBuilder, cfg *findConfig) string {
+	if len(cfg.joins) > 0 && c.softDelete != nil {
+		sd := *c.softDelete
+		sd.column = tableName[T]() + "." + sd.column
+		scoped := *c
+		scoped.softDelete = &sd
+		c = &scoped
+	}
+	return c.scopedWhere(b, cfg.where, cfg.trashed)
+}
+
+// buildFindWhere is findWhere with its own ArgBuilder.
+func (c *Curd[T]) buildFindWhere(cfg *findConfig) (clause string, args []any, err error) {
+	b := newArgBuilder(c.dialect, 1)
+	clause = c.findWhere(b, cfg)
+	return clause, b.ArgsSlice(), b.err
 }
 
 // --- Query methods ---
 
 // FindAll returns all rows matching the predicate, ordered and paginated.
-// Pass nil for where to include all rows. orderBy can be empty.
+// Pass nil for where to include all rows. orderBy can be empty; otherwise
+// it is parsed like WithOrderBy.
 func (c *Curd[T]) FindAll(ctx context.Context, where Predicate, orderBy string, limit, offset int) ([]T, error) {
 	var t T
 	name := tableName[T]()
-	cols := columnsFromType(reflect.TypeOf(t), c.fm)
+	cols := c.quoteAll(columnsFromType(reflect.TypeOf(t), c.fm))
 
-	whereClause, args := c.buildWhereClause(where)
+	whereClause, args, err := c.buildWhereClause(where)
+	if err != nil {
+		return nil, fmt.Errorf("findAll %s: %w", name, err)
+	}
 
-	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(cols, ","), name, whereClause)
+	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(cols, ","), c.quote(name), whereClause)
 	if orderBy != "" {
-		query += " ORDER BY " + orderBy
-	}
-	nextIdx := len(args) + 1
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT %s", c.dialect.Placeholder(nextIdx))
-		args = append(args, limit)
-		nextIdx++
-	}
-	if offset > 0 {
-		query += fmt.Sprintf(" OFFSET %s", c.dialect.Placeholder(nextIdx))
-		args = append(args, offset)
+		orders, err := parseOrderBy(orderBy)
+		if err != nil {
+			return nil, fmt.Errorf("findAll %s: %w", name, err)
+		}
+		orderSQL, err := c.orderBySQL(orders)
+		if err != nil {
+			return nil, fmt.Errorf("findAll %s: %w", name, err)
+		}
+		query += " ORDER BY " + orderSQL
 	}
+	var pageClause string
+	pageClause, args = c.limitOffset(args, limit, offset)
+	query += pageClause
 
 	defer c.logSQL(ctx, query, args...)()
-	rows, err := c.q.Query(ctx, query, args...)
+	rows, err := c.db(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("findAll %s: %w", name, err)
 	}
 	defer rows.Close()
-	return scanAllWithMapper[T](rows, c.fm)
+	return scanAllWithMapper[T](ctx, rows, c.fm, c.decoder())
 }
 
-// FindOne returns a single row matching the predicate, or an error if not found.
+// FindOne returns a single row matching the predicate, or an error matching
+// ErrNotFound if there is none.
 func (c *Curd[T]) FindOne(ctx context.Context, where Predicate) (T, error) {
 	var zero T
 	results, err := c.FindAll(ctx, where, "", 1, 0)
@@ -258,194 +459,333 @@ func (c *Curd[T]) FindOne(ctx context.Context, where Predicate) (T, error) {
 		return zero, err
 	}
 	if len(results) == 0 {
-		return zero, fmt.Errorf("%T not found", zero)
+		return zero, fmt.Errorf("find %s: %w", tableName[T](), ErrNotFound)
 	}
 	return results[0], nil
 }
 
-// FindByID returns a single row by its primary key "id".
-func (c *Curd[T]) FindByID(ctx context.Context, id any) (T, error) {
-	return c.FindOne(ctx, Eq("id", id))
+// FindByID returns a single row by its primary key. id is either the key
+// value(s) in primary-key field order, a Key built with PK, or a struct
+// carrying the key fields. See the pk tag on primaryKeyFields.
+//
+// Usage:
+//
+//	row, err := c.FindByID(ctx, 42)
+//	row, err := c.FindByID(ctx, tenantID, "INV-001") // composite key
+func (c *Curd[T]) FindByID(ctx context.Context, id ...any) (T, error) {
+	pred, err := c.pkPredicate(id...)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("findByID %w", err)
+	}
+	return c.FindOne(ctx, pred)
 }
 
 // Find is a general-purpose query method driven by functional options.
 // It supports JOINs, column selection, filtering, ordering, and pagination.
+// Selected and sort columns must be columns of T, or columns of a joined
+// table qualified with its name or alias (see ErrUnknownColumn).
 //
-// Usage:
+// Usage — users has a Label field filled from the joined roles table:
 //
-//	results, err := c.Find(ctx,
-//	    curd.WithJoins(curd.JoinClause{Type: curd.LeftJoin, Table: "role AS r", On: "r.name = t.role_name"}),
-//	    curd.WithColumns("t.id", "t.name", "r.label"),
-//	    curd.WithWhere(curd.Eq("t.status", "active")),
-//	    curd.WithOrderBy("t.id ASC"),
+//	results, err := users.Find(ctx,
+//	    curd.WithJoins(curd.JoinClause{Type: curd.LeftJoin, Table: "roles AS r", On: "r.name = users.role_name"}),
+//	    curd.WithColumns("users.id", "users.name", "r.label"),
+//	    curd.WithWhere(curd.Eq("users.status", "active")),
+//	    curd.WithOrderBy("r.label ASC, users.id ASC"),
 //	    curd.WithLimit(10),
 //	)
 func (c *Curd[T]) Find(ctx context.Context, opts ...FindOption) ([]T, error) {
-	cfg := resolveFindConfig(opts)
+	return c.find(ctx, resolveFindConfig(opts))
+}
 
-	var t T
-	name := tableName[T]()
+// find runs the SELECT described by cfg and scans every row into T.
+func (c *Curd[T]) find(ctx context.Context, cfg *findConfig) ([]T, error) {
+	query, args, err := c.buildSelect(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", tableName[T](), err)
+	}
+	defer c.logSQL(ctx, query, args...)()
+	rows, err := c.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", tableName[T](), err)
+	}
+	defer rows.Close()
+	results, err := scanAllWithMapper[T](ctx, rows, c.fm, c.decoder())
+	if err != nil {
+		return nil, err
+	}
+	if err := c.preloadRows(ctx, results, cfg.preload); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
+// buildSelect renders the SELECT statement described by cfg. Selected and
+// sort columns must be columns of T (see ErrUnknownColumn).
+func (c *Curd[T]) buildSelect(ctx context.Context, cfg *findConfig) (string, []any, error) {
+	b := newArgBuilder(c.dialect, 1)
+	query, err := c.renderSelect(ctx, cfg, b)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, b.ArgsSlice(), nil
+}
+
+// renderSelect is buildSelect binding the statement's values through b, so
+// that it can be embedded in another statement (see Subquery).
+func (c *Curd[T]) renderSelect(ctx context.Context, cfg *findConfig, b *ArgBuilder) (string, error) {
+	c = c.withJoins(cfg)
+	var t T
 	cols := cfg.columns
 	if len(cols) == 0 {
 		cols = columnsFromType(reflect.TypeOf(t), c.fm)
+	} else {
+		var err error
+		if cols, err = c.resolveColumns(cols); err != nil {
+			return "", err
+		}
 	}
+	return c.renderSelectList(ctx, strings.Join(c.quoteAll(cols), ","), cfg, b)
+}
 
-	fromClause := name
-	for _, j := range cfg.joins {
-		fromClause += fmt.Sprintf(" %s JOIN %s ON %s", j.Type, j.Table, j.On)
+// renderSelectList renders the SELECT statement described by cfg with the
+// given select list. ctx decides whether a row lock runs in a transaction.
+func (c *Curd[T]) renderSelectList(ctx context.Context, list string, cfg *findConfig, b *ArgBuilder) (string, error) {
+	orderBy, err := c.withJoins(cfg).orderByClause(cfg)
+	if err != nil {
+		return "", err
 	}
 
-	whereClause, args := c.buildWhereClause(cfg.where)
+	lock, err := c.lockSQL(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
 
-	query := fmt.Sprintf("SELECT %s FROM %s%s", strings.Join(cols, ","), fromClause, whereClause)
-	if cfg.orderBy != "" {
-		query += " ORDER BY " + cfg.orderBy
+	whereClause := c.findWhere(b, cfg)
+	if b.err != nil {
+		return "", b.err
 	}
-	nextIdx := len(args) + 1
-	if cfg.limit > 0 {
-		query += fmt.Sprintf(" LIMIT %s", c.dialect.Placeholder(nextIdx))
-		args = append(args, cfg.limit)
-		nextIdx++
+	query := fmt.Sprintf("SELECT %s FROM %s%s", list, c.fromClause(cfg), whereClause)
+	if orderBy != "" {
+		query += " ORDER BY " + orderBy
 	}
-	if cfg.offset > 0 {
-		query += fmt.Sprintf(" OFFSET %s", c.dialect.Placeholder(nextIdx))
-		args = append(args, cfg.offset)
+	return query + c.pageClause(b, cfg.limit, cfg.offset) + lock, nil
+}
+
+// fromClause renders T's table followed by any JOINs in cfg.
+func (c *Curd[T]) fromClause(cfg *findConfig) string {
+	from := c.quote(tableName[T]())
+	for _, j := range cfg.joins {
+		from += fmt.Sprintf(" %s JOIN %s ON %s", j.Type, j.Table, j.On)
 	}
+	return from
+}
 
-	defer c.logSQL(ctx, query, args...)()
-	rows, err := c.q.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("find %s: %w", name, err)
+// orderByClause renders cfg's typed orders, falling back to the parsed
+// WithOrderBy string.
+func (c *Curd[T]) orderByClause(cfg *findConfig) (string, error) {
+	orders := cfg.orders
+	if len(orders) == 0 {
+		if cfg.orderBy == "" {
+			return "", nil
+		}
+		var err error
+		if orders, err = parseOrderBy(cfg.orderBy); err != nil {
+			return "", err
+		}
 	}
-	defer rows.Close()
-	return scanAllWithMapper[T](rows, c.fm)
+	return c.orderBySQL(orders)
 }
 
 // FindPaginated returns a page of results together with the total count.
 // The count query wraps the same FROM/JOIN/WHERE in a subquery to correctly
 // handle JOINs.
 func (c *Curd[T]) FindPaginated(ctx context.Context, opts ...FindOption) (*PaginatedResult[T], error) {
-	cfg := resolveFindConfig(opts)
-	// Don't allow limit/offset on the count query
-	countCfg := *cfg
-	countCfg.limit = 0
-	countCfg.offset = 0
-	countCfg.orderBy = ""
-	countCfg.columns = []string{"1"}
+	total, err := c.countFound(ctx, resolveFindConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.Find(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResult[T]{List: list, Total: total}, nil
+}
 
+// countFound counts the rows matched by cfg's joins, predicate and
+// soft-delete scope, ignoring its columns, order and page.
+func (c *Curd[T]) countFound(ctx context.Context, cfg *findConfig) (int64, error) {
 	name := tableName[T]()
+	fromClause := c.fromClause(cfg)
 
-	fromClause := name
-	for _, j := range cfg.joins {
-		fromClause += fmt.Sprintf(" %s JOIN %s ON %s", j.Type, j.Table, j.On)
+	whereClause, whereArgs, err := c.buildFindWhere(cfg)
+	if err != nil {
+		return 0, fmt.Errorf("findPaginated count %s: %w", name, err)
 	}
 
-	whereClause, whereArgs := c.buildWhereClause(cfg.where)
-
 	// COUNT uses a subquery to handle JOINs correctly
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM %s%s) AS _curd_count", fromClause, whereClause)
 	var total int64
 	defer c.logSQL(ctx, countQuery, whereArgs...)()
-	if err := c.q.QueryRow(ctx, countQuery, whereArgs...).Scan(&total); err != nil {
-		return nil, fmt.Errorf("findPaginated count %s: %w", name, err)
+	if err := c.db(ctx).QueryRow(ctx, countQuery, whereArgs...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("findPaginated count %s: %w", name, err)
 	}
-
-	list, err := c.Find(ctx, opts...)
-	if err != nil {
-		return nil, err
-	}
-
-	return &PaginatedResult[T]{List: list, Total: total}, nil
+	return total, nil
 }
 
 // --- Insert methods ---
 
 // InsertOne inserts a single row. If the entity has an ID field, the generated
-// id is set back on the row via RETURNING. CreatedDate and ChangedDate are
-// auto-set to time.Now().
+// id is set back on the row via RETURNING, or via LastInsertIDResult for
+// dialects without RETURNING support. CreatedDate and ChangedDate (see
+// WithTimestamps) are auto-set to time.Now().
 func (c *Curd[T]) InsertOne(ctx context.Context, row *T) error {
 	v := reflect.ValueOf(row).Elem()
-	t := v.Type()
 	tableName := (*row).TableName()
 
-	setNow(v, "CreatedDate")
-	setNow(v, "ChangedDate")
+	c.stampCreated(v)
+	if err := beforeInsert(ctx, v); err != nil {
+		return fmt.Errorf("insert %s: %w", tableName, err)
+	}
 
 	cols, vals := rowValues(v, c.fm, c.transforms...)
-	placeholders := make([]string, len(vals))
-	args := make([]any, len(vals))
-	for i := range vals {
-		placeholders[i] = c.dialect.Placeholder(i + 1)
-		args[i] = vals[i]
+	query, args := c.insertSQL(tableName, cols, [][]any{vals})
+	if err := c.execInsertReturningID(ctx, v, query, args); err != nil {
+		return fmt.Errorf("insert %s: %w", tableName, err)
 	}
-
-	returningClause := ""
-	if hasField(t, "ID") {
-		returningClause = " RETURNING id"
+	if err := afterInsert(ctx, v); err != nil {
+		return fmt.Errorf("insert %s: %w", tableName, err)
 	}
+	return nil
+}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s",
-		tableName, strings.Join(cols, ","), strings.Join(placeholders, ","), returningClause)
+// insertSQL renders "INSERT INTO table (cols) VALUES (...),(...)" for the
+// given value tuples and returns the statement with its flattened args.
+func (c *Curd[T]) insertSQL(tableName string, cols []string, tuples [][]any) (string, []any) {
+	placeholders := make([]string, len(tuples))
+	args := make([]any, 0, len(tuples)*len(cols))
+	for i, vals := range tuples {
+		ph := make([]string, len(vals))
+		for j := range vals {
+			args = append(args, vals[j])
+			ph[j] = c.dialect.Placeholder(len(args))
+		}
+		placeholders[i] = "(" + strings.Join(ph, ",") + ")"
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		c.quote(tableName), strings.Join(c.quoteAll(cols), ","), strings.Join(placeholders, ","))
+	return query, args
+}
 
-	if returningClause != "" {
+// execInsertReturningID runs a single-row INSERT and writes a database
+// generated primary key back to v, using RETURNING when the dialect supports
+// it and LastInsertIDResult otherwise.
+func (c *Curd[T]) execInsertReturningID(ctx context.Context, v reflect.Value, query string, args []any) error {
+	pk := c.generatedPK()
+	if pk == nil {
 		defer c.logSQL(ctx, query, args...)()
-		var id int64
-		if err := c.q.QueryRow(ctx, query, args...).Scan(&id); err != nil {
-			return fmt.Errorf("insert %s: %w", tableName, err)
-		}
-		setField(v, "ID", id)
-		return nil
+		_, err := c.db(ctx).Exec(ctx, query, args...)
+		return err
+	}
+	if c.dialect.SupportsReturning() {
+		return c.scanGeneratedKey(ctx, v, pk, query, args)
 	}
 	defer c.logSQL(ctx, query, args...)()
-	_, err := c.q.Exec(ctx, query, args...)
+	res, err := c.db(ctx).Exec(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("insert %s: %w", tableName, err)
+		return err
 	}
-	return nil
+	return setLastInsertID(v, pk, res)
 }
 
-// InsertBatch inserts multiple rows in a single statement.
-// CreatedDate and ChangedDate are auto-set on each row.
+// InsertBatch inserts multiple rows. CreatedDate and ChangedDate are
+// auto-set and FieldTransformers applied on each row.
+//
+// Batches of at least the copy threshold (see WithCopyThreshold) are loaded
+// through the Querier's BulkCopier when it implements one. Otherwise rows
+// are sent as multi-row INSERT statements, split into chunks that stay under
+// the 65535 bind-parameter limit. Chunks are separate statements; run the
+// call inside WithTx when the whole batch must be atomic.
 func (c *Curd[T]) InsertBatch(ctx context.Context, rows []T) error {
 	if len(rows) == 0 {
 		return nil
 	}
 	tableName := rows[0].TableName()
 
-	pv0 := reflect.ValueOf(&rows[0])
-	setNow(pv0, "CreatedDate")
-	setNow(pv0, "ChangedDate")
-	cols, _ := rowValues(pv0.Elem(), c.fm, c.transforms...)
+	cols, tuples, err := c.batchValues(ctx, rows)
+	if err != nil {
+		return fmt.Errorf("insert batch %s: %w", tableName, err)
+	}
 
-	placeholders := make([]string, len(rows))
-	args := make([]any, 0, len(rows)*len(cols))
-	argIdx := 1
-	for i := range rows {
-		pv := reflect.ValueOf(&rows[i])
-		if i > 0 {
-			setNow(pv, "CreatedDate")
-			setNow(pv, "ChangedDate")
+	if bc, ok := c.querier(ctx).(BulkCopier); ok && c.copyThreshold > 0 && len(rows) >= c.copyThreshold {
+		done := c.logSQL(ctx, fmt.Sprintf("COPY %s (%s) FROM STDIN", c.quote(tableName), strings.Join(c.quoteAll(cols), ",")))
+		_, err := bc.CopyFrom(ctx, tableName, cols, tuples)
+		err = translateErr(c.translator(), err)
+		done()
+		if err != nil {
+			return fmt.Errorf("insert batch %s: copy: %w", tableName, err)
 		}
-		_, vals := rowValues(pv.Elem(), c.fm, c.transforms...)
-		ph := make([]string, len(vals))
-		for j := range vals {
-			ph[j] = c.dialect.Placeholder(argIdx)
-			argIdx++
+	} else {
+		for _, chunk := range chunkTuples(tuples, len(cols)) {
+			query, args := c.insertSQL(tableName, cols, chunk)
+			if err := c.execLogged(ctx, query, args); err != nil {
+				return fmt.Errorf("insert batch %s: %w", tableName, err)
+			}
 		}
-		placeholders[i] = "(" + strings.Join(ph, ",") + ")"
-		args = append(args, vals...)
 	}
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", tableName, strings.Join(cols, ","), strings.Join(placeholders, ","))
-	defer c.logSQL(ctx, query, args...)()
-	_, err := c.q.Exec(ctx, query, args...)
-	if err != nil {
-		return fmt.Errorf("insert batch %s: %w", tableName, err)
+	for i := range rows {
+		if err := afterInsert(ctx, reflect.ValueOf(&rows[i]).Elem()); err != nil {
+			return fmt.Errorf("insert batch %s: %w", tableName, err)
+		}
 	}
 	return nil
 }
 
+// execLogged runs a statement with SQL logging, discarding its Result.
+func (c *Curd[T]) execLogged(ctx context.Context, query string, args []any) error {
+	defer c.logSQL(ctx, query, args...)()
+	_, err := c.db(ctx).Exec(ctx, query, args...)
+	return err
+}
+
+// chunkTuples splits tuples so that no chunk binds more than maxBindParams
+// parameters when each tuple has width values.
+func chunkTuples(tuples [][]any, width int) [][][]any {
+	size := len(tuples)
+	if width > 0 {
+		size = max(maxBindParams/width, 1)
+	}
+	var chunks [][][]any
+	for start := 0; start < len(tuples); start += size {
+		chunks = append(chunks, tuples[start:min(start+size, len(tuples))])
+	}
+	return chunks
+}
+
+// batchValues sets CreatedDate/ChangedDate and runs the BeforeInsert hook on
+// every row, then returns the column list (taken from the first row) with
+// one value tuple per row.
+func (c *Curd[T]) batchValues(ctx context.Context, rows []T) (cols []string, tuples [][]any, err error) {
+	tuples = make([][]any, len(rows))
+	for i := range rows {
+		pv := reflect.ValueOf(&rows[i])
+		c.stampCreated(pv)
+		if err := beforeInsert(ctx, pv.Elem()); err != nil {
+			return nil, nil, err
+		}
+		rowCols, vals := rowValues(pv.Elem(), c.fm, c.transforms...)
+		if i == 0 {
+			cols = rowCols
+		}
+		tuples[i] = vals
+	}
+	return cols, tuples, nil
+}
+
 // InsertBatchPtr inserts multiple rows (given as pointers) in a single statement.
 // nil elements become zero-value rows.
 func (c *Curd[T]) InsertBatchPtr(ctx context.Context, rows []*T) error {
@@ -460,91 +800,175 @@ func (c *Curd[T]) InsertBatchPtr(ctx context.Context, rows []*T) error {
 
 // --- Update methods ---
 
-// UpdateByID updates a row identified by its primary key "id".
+// UpdateByID updates a row identified by its primary key. id accepts the
+// same forms as FindByID's single argument: a scalar, a Key or a key struct.
+// Include the version column in updates to make the update version-checked.
 func (c *Curd[T]) UpdateByID(ctx context.Context, id any, updates map[string]any) error {
-	tableName := tableName[T]()
-	setClauses := make([]string, 0, len(updates))
-	args := make([]any, 1, 1+len(updates))
-	args[0] = id
-	argIdx := 2
-	for col, val := range updates {
-		setClauses = append(setClauses, fmt.Sprintf("%s = %s", col, c.dialect.Placeholder(argIdx)))
-		args = append(args, val)
-		argIdx++
-	}
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = %s", tableName, strings.Join(setClauses, ","), c.dialect.Placeholder(1))
-	defer c.logSQL(ctx, query, args...)()
-	_, err := c.q.Exec(ctx, query, args...)
+	key, err := c.keyValues(id)
 	if err != nil {
-		return fmt.Errorf("update %s: %w", tableName, err)
+		return fmt.Errorf("update %w", err)
 	}
-	return nil
+	pred := c.keyPredicate(key)
+	if err := beforeScopedUpdate[T](ctx, WriteScope{Key: key, Where: pred}, updates); err != nil {
+		return fmt.Errorf("update %s: %w", tableName[T](), err)
+	}
+	return c.updateWhere(ctx, pred, updates)
 }
 
-// UpdateWhere updates rows matching the predicate.
+// UpdateWhere updates rows matching the predicate. The ChangedDate column
+// (see WithTimestamps) is set to the current time unless updates sets it.
+//
+// For versioned entities (see versionFieldOf) the version column is
+// incremented; if updates holds the version that was read, only rows still
+// at that version are updated and ErrStaleObject is returned when none are.
 func (c *Curd[T]) UpdateWhere(ctx context.Context, where Predicate, updates map[string]any) error {
+	if err := beforeScopedUpdate[T](ctx, WriteScope{Where: where}, updates); err != nil {
+		return fmt.Errorf("update where %s: %w", tableName[T](), err)
+	}
+	return c.updateWhere(ctx, where, updates)
+}
+
+// updateWhere is UpdateWhere without the BeforeScopedUpdate hook.
+func (c *Curd[T]) updateWhere(ctx context.Context, where Predicate, updates map[string]any) error {
 	tableName := tableName[T]()
+	updates, err := c.resolveUpdates(updates)
+	if err != nil {
+		return fmt.Errorf("update where %s: %w", tableName, err)
+	}
 
 	// Build SET clause (starts at $1)
-	setClauses := make([]string, 0, len(updates))
-	args := make([]any, 0, len(updates)+4) // +4 for typical WHERE args
+	setClauses := make([]string, 0, len(updates)+2)
+	args := make([]any, 0, len(updates)+5) // +4 for typical WHERE args
 	argIdx := 1
 	for col, val := range updates {
-		setClauses = append(setClauses, fmt.Sprintf("%s = %s", col, c.dialect.Placeholder(argIdx)))
+		if c.version != nil && col == c.version.column {
+			continue
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s", c.quote(col), c.dialect.Placeholder(argIdx)))
 		args = append(args, val)
 		argIdx++
 	}
+	if col, now := c.changedColumn(time.Now()); col != "" {
+		if _, ok := updates[col]; !ok {
+			setClauses = append(setClauses, fmt.Sprintf("%s = %s", c.quote(col), c.dialect.Placeholder(argIdx)))
+			args = append(args, now)
+			argIdx++
+		}
+	}
+	var expectedVersion any
+	checkVersion := false
+	if c.version != nil {
+		verCol := c.quote(c.version.column)
+		setClauses = append(setClauses, fmt.Sprintf("%s = %s + 1", verCol, verCol))
+		expectedVersion, checkVersion = updates[c.version.column]
+	}
 
 	// Build WHERE from predicate
-	whereClause, whereArgs := buildPredicate(where, c.dialect)
-	whereSQL := ""
+	whereClause, whereArgs, err := buildPredicate(where, c.dialect)
+	if err != nil {
+		return fmt.Errorf("update where %s: %w", tableName, err)
+	}
+	var conds []string
 	if whereClause != "" {
 		// Re-number where placeholders to continue after SET args
-		renumbered := renumberPlaceholders(whereClause, c.dialect, argIdx)
-		whereSQL = " WHERE " + renumbered
+		conds = append(conds, renumberPlaceholders(whereClause, c.dialect, argIdx))
 		args = append(args, whereArgs...)
 	}
+	if checkVersion {
+		args = append(args, expectedVersion)
+		conds = append(conds, fmt.Sprintf("%s = %s", c.quote(c.version.column), c.dialect.Placeholder(len(args))))
+	}
+	whereSQL := ""
+	if len(conds) > 0 {
+		whereSQL = " WHERE " + strings.Join(conds, " AND ")
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s%s", tableName, strings.Join(setClauses, ","), whereSQL)
+	query := fmt.Sprintf("UPDATE %s SET %s%s", c.quote(tableName), strings.Join(setClauses, ","), whereSQL)
 	defer c.logSQL(ctx, query, args...)()
-	_, err := c.q.Exec(ctx, query, args...)
+	res, err := c.db(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("update where %s: %w", tableName, err)
 	}
+	// An unknown count (-1) cannot prove the row stale.
+	if checkVersion && res.RowsAffected() == 0 {
+		return fmt.Errorf("update where %s: %w", tableName, ErrStaleObject)
+	}
 	return nil
 }
 
 // --- Delete methods ---
 
 // DeleteByID deletes a row by its primary key. If hard is false, performs a
-// soft delete by setting deleted_date. If hard is true, performs a hard DELETE.
+// soft delete by setting the soft-delete column (deleted_date by default,
+// see WithSoftDelete) on the row if it is still live. If hard is true,
+// performs a hard DELETE.
+// id accepts the same forms as UpdateByID.
 func (c *Curd[T]) DeleteByID(ctx context.Context, id any, hard bool) error {
 	tableName := tableName[T]()
+	key, err := c.keyValues(id)
+	if err != nil {
+		return fmt.Errorf("delete %w", err)
+	}
+	pred := c.keyPredicate(key)
+	if err := beforeScopedDelete[T](ctx, WriteScope{Key: key, Where: pred}); err != nil {
+		return fmt.Errorf("delete %s: %w", tableName, err)
+	}
+	if err := c.beforeDeleteRow(ctx, pred, hard); err != nil {
+		return fmt.Errorf("delete %s: %w", tableName, err)
+	}
 	if hard {
-		query := fmt.Sprintf("DELETE FROM %s WHERE id = %s", tableName, c.dialect.Placeholder(1))
-		defer c.logSQL(ctx, query, id)()
-		_, err := c.q.Exec(ctx, query, id)
+		err = c.forceDelete(ctx, pred)
+	} else {
+		sd := c.softDelete
+		if sd == nil {
+			return fmt.Errorf("delete %s: no soft-delete column, pass hard=true", tableName)
+		}
+		// Only live rows: deleting a trashed row again keeps its timestamp.
+		err = c.setSoftDeleted(ctx, pred, sd.deletedValue(time.Now()), sd.liveSQL(c.quote(sd.column)))
+	}
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// beforeDeleteRow loads the row DeleteByID is about to delete and runs its
+// BeforeDelete hook, if T implements BeforeDeleter. A soft delete only
+// looks at live rows.
+func (c *Curd[T]) beforeDeleteRow(ctx context.Context, pred Predicate, hard bool) error {
+	if _, ok := hookTarget(newT[T]()).(BeforeDeleter); !ok {
+		return nil
+	}
+	opts := []FindOption{WithWhere(pred), WithLimit(1)}
+	if hard {
+		opts = append(opts, WithTrashed())
+	}
+	rows, err := c.find(ctx, resolveFindConfig(opts))
+	if err != nil || len(rows) == 0 {
 		return err
 	}
-	query := fmt.Sprintf("UPDATE %s SET deleted_date = %s WHERE id = %s", tableName, c.dialect.Placeholder(1), c.dialect.Placeholder(2))
-	args := []any{time.Now(), id}
-	defer c.logSQL(ctx, query, args...)()
-	_, err := c.q.Exec(ctx, query, args...)
-	return err
+	return beforeDelete(ctx, reflect.ValueOf(&rows[0]).Elem())
 }
 
-// DeleteWhere hard-deletes rows matching the predicate.
+// DeleteWhere deletes rows matching the predicate. When the entity has the
+// soft-delete field, live matching rows are soft-deleted; otherwise rows
+// are hard-deleted. Use ForceDelete to always hard-delete.
 func (c *Curd[T]) DeleteWhere(ctx context.Context, where Predicate) error {
 	tableName := tableName[T]()
-	whereClause, args := buildPredicate(where, c.dialect)
-	whereSQL := ""
-	if whereClause != "" {
-		whereSQL = " WHERE " + whereClause
+	if err := beforeScopedDelete[T](ctx, WriteScope{Where: where}); err != nil {
+		return fmt.Errorf("delete where %s: %w", tableName, err)
 	}
-	query := fmt.Sprintf("DELETE FROM %s%s", tableName, whereSQL)
-	defer c.logSQL(ctx, query, args...)()
-	_, err := c.q.Exec(ctx, query, args...)
-	return err
+	sd := c.softDelete
+	var err error
+	if sd == nil || !sd.onField {
+		err = c.forceDelete(ctx, where)
+	} else {
+		err = c.setSoftDeleted(ctx, where, sd.deletedValue(time.Now()), sd.liveSQL(c.quote(sd.column)))
+	}
+	if err != nil {
+		return fmt.Errorf("delete where %s: %w", tableName, err)
+	}
+	return nil
 }
 
 // --- Aggregate methods ---
@@ -553,11 +977,14 @@ func (c *Curd[T]) DeleteWhere(ctx context.Context, where Predicate) error {
 // Soft-deleted rows (deleted_date IS NOT NULL) are automatically excluded.
 func (c *Curd[T]) Count(ctx context.Context, where Predicate) (int64, error) {
 	tableName := tableName[T]()
-	whereClause, args := c.buildWhereClause(where)
-	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", tableName, whereClause)
+	whereClause, args, err := c.buildWhereClause(where)
+	if err != nil {
+		return 0, fmt.Errorf("count %s: %w", tableName, err)
+	}
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", c.quote(tableName), whereClause)
 	var count int64
 	defer c.logSQL(ctx, query, args...)()
-	err := c.q.QueryRow(ctx, query, args...).Scan(&count)
+	err = c.db(ctx).QueryRow(ctx, query, args...).Scan(&count)
 	return count, err
 }
 
@@ -565,15 +992,18 @@ func (c *Curd[T]) Count(ctx context.Context, where Predicate) (int64, error) {
 // Soft-deleted rows are automatically excluded.
 func (c *Curd[T]) Exists(ctx context.Context, where Predicate) (bool, error) {
 	tableName := tableName[T]()
-	whereClause, args := c.buildWhereClause(where)
+	whereClause, args, err := c.buildWhereClause(where)
+	if err != nil {
+		return false, fmt.Errorf("exists %s: %w", tableName, err)
+	}
 	whereSQL := ""
 	if whereClause != "" {
 		whereSQL = whereClause
 	}
 	var exists bool
-	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s%s)", tableName, whereSQL)
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s%s)", c.quote(tableName), whereSQL)
 	defer c.logSQL(ctx, query, args...)()
-	err := c.q.QueryRow(ctx, query, args...).Scan(&exists)
+	err = c.db(ctx).QueryRow(ctx, query, args...).Scan(&exists)
 	return exists, err
 }
 
@@ -588,7 +1018,10 @@ func (c *Curd[T]) Exists(ctx context.Context, where Predicate) (bool, error) {
 // case.
 //
 // This is NOT an atomic operation — it runs a SELECT followed by INSERT
-// or UPDATE. It does not require database constraints.
+// or UPDATE. It does not require database constraints. Use UpsertOnConflict
+// when a unique constraint exists and writers may race, or a version field
+// to have concurrent updates fail with ErrStaleObject instead of the later
+// write silently winning.
 //
 // Usage:
 //
@@ -606,36 +1039,38 @@ func (c *Curd[T]) Upsert(ctx context.Context, where Predicate, row *T) error {
 	}
 
 	if exists {
-		setNow(v, "ChangedDate")
-		updates := structToUpdates(v, c.fm, c.transforms)
-		return c.UpdateWhere(ctx, where, updates)
+		return c.updateRow(ctx, where, v)
 	}
 
-	setNow(v, "CreatedDate")
-	setNow(v, "ChangedDate")
 	return c.InsertOne(ctx, row)
 }
 
-// Save upserts by primary key "id":
-//   - If row.ID is zero → INSERT
-//   - If row.ID is non-zero → check if exists; UPDATE all row fields if yes,
-//     INSERT if no
+// Save upserts by primary key:
+//   - If the entity has no primary key, or its single (generated) key is
+//     zero → INSERT
+//   - Otherwise → check if exists; UPDATE all row fields if yes, INSERT if no
 //
-// This is a convenience wrapper around Upsert with an id-based predicate.
+// This is a convenience wrapper around Upsert with a key-based predicate.
 func (c *Curd[T]) Save(ctx context.Context, row *T) error {
 	v := reflect.ValueOf(row).Elem()
 
-	idField := v.FieldByName("ID")
-	if !idField.IsValid() || idField.IsZero() {
+	if pk := c.generatedPK(); pk != nil && readField(derefValue(v), pk.index, pk.typ).IsZero() {
 		return c.InsertOne(ctx, row)
 	}
-
-	return c.Upsert(ctx, Eq("id", idField.Interface()), row)
+	values, ok := pkValues(v, c.pk)
+	if !ok {
+		return c.InsertOne(ctx, row)
+	}
+	pred, err := c.pkPredicate(Key(values))
+	if err != nil {
+		return fmt.Errorf("save %w", err)
+	}
+	return c.Upsert(ctx, pred, row)
 }
 
 // structToUpdates converts a struct value to a map[string]any suitable for
-// UpdateWhere. All mapped columns are included (zero values included).
-// Field transformers are applied.
+// UpdateWhere. All mapped columns except the primary key are included
+// (zero values included). Field transformers are applied.
 func structToUpdates(v reflect.Value, fm FieldMapper, transforms []FieldTransformer) map[string]any {
 	for v.Kind() == reflect.Ptr {
 		if v.IsNil() {
@@ -644,14 +1079,14 @@ func structToUpdates(v reflect.Value, fm FieldMapper, transforms []FieldTransfor
 		v = v.Elem()
 	}
 	t := v.Type()
+	pk := primaryKeyFields(t, fm)
 	updates := make(map[string]any)
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		col := fm.ColumnName(f)
-		if col == "" || col == "id" {
+	for _, f := range flatFields(t) {
+		col := f.columnName(fm)
+		if col == "" || isPKColumn(pk, col) || (len(pk) == 0 && col == "id") {
 			continue
 		}
-		val := v.Field(i).Interface()
+		val := readField(v, f.Index, f.Type).Interface()
 		for _, tr := range transforms {
 			val = tr(col, val)
 		}
@@ -662,17 +1097,25 @@ func structToUpdates(v reflect.Value, fm FieldMapper, transforms []FieldTransfor
 
 // --- Utility methods ---
 
-// Pluck extracts values of a single column into a slice.
+// Pluck extracts values of a single column into a slice. column must be a
+// column of T (see ErrUnknownColumn).
 //
 // Usage:
 //
 //	names, err := c.Pluck(ctx, "name", curd.Eq("status", "active"))
 func (c *Curd[T]) Pluck(ctx context.Context, column string, where Predicate) ([]any, error) {
 	tableName := tableName[T]()
-	whereClause, args := c.buildWhereClause(where)
-	query := fmt.Sprintf("SELECT %s FROM %s%s", column, tableName, whereClause)
+	column, err := c.columns.resolve(column)
+	if err != nil {
+		return nil, fmt.Errorf("pluck %s: %w", tableName, err)
+	}
+	whereClause, args, err := c.buildWhereClause(where)
+	if err != nil {
+		return nil, fmt.Errorf("pluck %s: %w", tableName, err)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s%s", c.quote(column), c.quote(tableName), whereClause)
 	defer c.logSQL(ctx, query, args...)()
-	rows, err := c.q.Query(ctx, query, args...)
+	rows, err := c.db(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("pluck %s: %w", tableName, err)
 	}
@@ -693,6 +1136,10 @@ func (c *Curd[T]) Pluck(ctx context.Context, column string, where Predicate) ([]
 // calling fn for each batch. Iteration stops when fn returns an error or
 // all rows have been consumed.
 //
+// Batches are fetched with keyset pagination on the Curd's each key (see
+// WithEachKey, default primary key ASC), so rows inserted or deleted while iterating
+// do not cause other rows to be skipped or repeated.
+//
 // Usage:
 //
 //	err := c.Each(ctx, curd.Gt("id", 0), 500, func(batch []T) error {
@@ -703,9 +1150,18 @@ func (c *Curd[T]) Each(ctx context.Context, where Predicate, batchSize int, fn f
 	if batchSize <= 0 {
 		batchSize = 500
 	}
-	offset := 0
+	orders := c.eachKey
+	if len(orders) == 0 {
+		orders = c.keyOrders()
+	}
+	orders, err := c.resolveOrders(orders)
+	if err != nil {
+		return fmt.Errorf("each %s: %w", tableName[T](), err)
+	}
+	cfg := &findConfig{where: where, limit: batchSize}
+	var after []any
 	for {
-		results, err := c.FindAll(ctx, where, "id ASC", batchSize, offset)
+		results, err := c.keysetPage(ctx, cfg, orders, after)
 		if err != nil {
 			return err
 		}
@@ -718,7 +1174,9 @@ func (c *Curd[T]) Each(ctx context.Context, where Predicate, batchSize int, fn f
 		if len(results) < batchSize {
 			return nil
 		}
-		offset += batchSize
+		if after, err = c.keysetValues(results[len(results)-1], orders); err != nil {
+			return err
+		}
 	}
 }
 
@@ -728,12 +1186,72 @@ func (c *Curd[T]) Each(ctx context.Context, where Predicate, batchSize int, fn f
 type FindOption func(*findConfig)
 
 type findConfig struct {
-	where   Predicate
-	joins   []JoinClause
-	columns []string
-	orderBy string
-	limit   int
-	offset  int
+	where      Predicate
+	joins      []JoinClause
+	columns    []string
+	orderBy    string
+	orders     []Order
+	limit      int
+	offset     int
+	trashed    trashedScope
+	groupBy    []string
+	having     Predicate
+	aggregates []Aggregation
+	preload    []string
+	lock       *lockClause
+}
+
+// Order is a single ORDER BY term on a column. Build it with Asc or Desc.
+// Column is a column or Go field name of the entity, optionally qualified
+// ("t.name"); unknown columns fail with ErrUnknownColumn.
+type Order struct {
+	Column string
+	Desc   bool
+	Nulls  NullsPlacement
+}
+
+// NullsPlacement places NULLs before or after the other values of an Order.
+// NULLS FIRST and NULLS LAST are not supported by MySQL.
+type NullsPlacement int
+
+const (
+	NullsDefault NullsPlacement = iota // database default
+	NullsFirst
+	NullsLast
+)
+
+// Asc returns an ascending Order on column.
+func Asc(column string) Order { return Order{Column: column} }
+
+// Desc returns a descending Order on column.
+func Desc(column string) Order { return Order{Column: column, Desc: true} }
+
+// NullsFirst returns o sorting NULLs before other values.
+func (o Order) NullsFirst() Order {
+	o.Nulls = NullsFirst
+	return o
+}
+
+// NullsLast returns o sorting NULLs after other values.
+//
+//	curd.WithOrder(curd.Desc("score").NullsLast(), curd.Asc("id"))
+func (o Order) NullsLast() Order {
+	o.Nulls = NullsLast
+	return o
+}
+
+func (o Order) direction() string {
+	dir := " ASC"
+	if o.Desc {
+		dir = " DESC"
+	}
+	switch o.Nulls {
+	case NullsFirst:
+		dir += " NULLS FIRST"
+	case NullsLast:
+		dir += " NULLS LAST"
+	}
+	return dir
 }
 
 // JoinType represents a SQL JOIN type.
@@ -769,16 +1287,27 @@ func WithJoins(joins ...JoinClause) FindOption {
 }
 
 // WithColumns specifies which columns to SELECT. If empty, all columns
-// are selected using the FieldMapper.
+// are selected using the FieldMapper. Each must be a column or Go field name
+// of the entity, optionally qualified ("t.name"); expressions are rejected
+// with ErrUnknownColumn.
 func WithColumns(cols ...string) FindOption {
 	return func(c *findConfig) { c.columns = append(c.columns, cols...) }
 }
 
-// WithOrderBy sets the ORDER BY clause.
+// WithOrderBy sets the ORDER BY clause from a string such as
+// "name ASC, created_date DESC NULLS LAST". Each term must name a column of
+// the entity (see ErrUnknownColumn); expressions are rejected, so the
+// string may come from user input.
 func WithOrderBy(orderBy string) FindOption {
 	return func(c *findConfig) { c.orderBy = orderBy }
 }
 
+// WithOrder sets the ORDER BY clause from typed terms. It takes precedence
+// over WithOrderBy and supplies the sort key for FindAfter.
+func WithOrder(orders ...Order) FindOption {
+	return func(c *findConfig) { c.orders = append(c.orders, orders...) }
+}
+
 // WithLimit sets the LIMIT clause.
 func WithLimit(n int) FindOption {
 	return func(c *findConfig) { c.limit = n }
@@ -799,7 +1328,9 @@ func resolveFindConfig(opts []FindOption) *findConfig {
 
 // renumberPlaceholders rewrites placeholder numbers in a SQL fragment
 // by adding an offset. For example, with offset=3, "$1 AND $2" becomes "$4 AND $5".
-// This is used when combining independently-built SQL fragments.
+// This is used when combining independently-built SQL fragments. Fragments
+// built for positional dialects ("?") contain no numbered placeholders and
+// are returned unchanged.
 func renumberPlaceholders(sql string, d Dialect, offset int) string {
 	if offset <= 1 {
 		return sql
@@ -834,30 +1365,31 @@ func renumberPlaceholders(sql string, d Dialect, offset int) string {
 // Column mapping uses Go field names directly (no json/gorm tag processing).
 func QueryRaw[T any](ctx context.Context, q Querier, query string, args ...any) ([]T, error) {
 	defer logSQLGlobal(ctx, query, args...)()
-	rows, err := q.Query(ctx, query, args...)
+	rows, err := translating(q, queryTranslator(q)).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query raw: %w", err)
 	}
 	defer rows.Close()
-	return scanAllWithMapper[T](rows, rawFieldMapper{})
+	return scanAllWithMapper[T](ctx, rows, rawFieldMapper{}, decoderFromContext(ctx))
 }
 
 // QueryRowRaw executes a raw SQL query and scans a single row into T.
 func QueryRowRaw[T any](ctx context.Context, q Querier, query string, args ...any) (T, error) {
 	var zero T
 	defer logSQLGlobal(ctx, query, args...)()
-	row := q.QueryRow(ctx, query, args...)
-	result, err := scanRowWithMapper[T](row, rawFieldMapper{})
+	row := translating(q, queryTranslator(q)).QueryRow(ctx, query, args...)
+	result, err := scanRowWithMapper[T](ctx, row, rawFieldMapper{}, decoderFromContext(ctx))
 	if err != nil {
 		return zero, err
 	}
 	return result, nil
 }
 
-// ExecRaw executes a raw SQL statement and returns the number of rows affected.
+// ExecRaw executes a raw SQL statement and returns the number of rows
+// affected, or -1 if the driver cannot report it.
 func ExecRaw(ctx context.Context, q Querier, sql string, args ...any) (int64, error) {
 	defer logSQLGlobal(ctx, sql, args...)()
-	tag, err := q.Exec(ctx, sql, args...)
+	tag, err := translating(q, queryTranslator(q)).Exec(ctx, sql, args...)
 	if err != nil {
 		return 0, fmt.Errorf("exec raw: %w", err)
 	}
@@ -935,29 +1467,48 @@ func nullSafeCopy(fields []reflect.Value, targets []any) {
 	}
 }
 
-func scanAllWithMapper[T any](rows Rows, fm FieldMapper) ([]T, error) {
+func scanAllWithMapper[T any](ctx context.Context, rows Rows, fm FieldMapper, d FieldDecoder) ([]T, error) {
 	var results []T
 	for rows.Next() {
-		elem := newT[T]()
-		targets, fields := scanTargets(elem, fm)
-		if err := rows.Scan(targets...); err != nil {
-			return nil, fmt.Errorf("scan row: %w", err)
+		elem, err := scanElem[T](ctx, rows, fm, d)
+		if err != nil {
+			return nil, err
 		}
-		nullSafeCopy(fields, targets)
-		results = append(results, elem.Interface().(T))
+		results = append(results, elem)
 	}
 	return results, rows.Err()
 }
 
-func scanRowWithMapper[T any](row Row, fm FieldMapper) (T, error) {
+func scanRowWithMapper[T any](ctx context.Context, row Row, fm FieldMapper, d FieldDecoder) (T, error) {
+	return scanElem[T](ctx, row, fm, d)
+}
+
+// scanElem scans the current row into a new T, applying the FieldDecoder d
+// (if any), and runs its AfterFind hook.
+func scanElem[T any](ctx context.Context, row Row, fm FieldMapper, d FieldDecoder) (T, error) {
 	var zero T
 	elem := newT[T]()
-	targets, fields := scanTargets(elem, fm)
+	if err := scanInto(ctx, row, elem, fm, d); err != nil {
+		return zero, err
+	}
+	return elem.Interface().(T), nil
+}
+
+// scanInto is scanElem for a struct (or pointer to struct) value elem
+// created by the caller.
+func scanInto(ctx context.Context, row Row, elem reflect.Value, fm FieldMapper, d FieldDecoder) error {
+	targets, fields, columns := scanTargets(elem, fm)
 	if err := row.Scan(targets...); err != nil {
-		return zero, fmt.Errorf("scan row: %w", err)
+		return fmt.Errorf("scan row: %w", err)
+	}
+	if err := decodeTargets(d, columns, fields, targets); err != nil {
+		return fmt.Errorf("scan row: %w", err)
 	}
 	nullSafeCopy(fields, targets)
-	return elem.Interface().(T), nil
+	if err := afterFind(ctx, elem); err != nil {
+		return fmt.Errorf("after find: %w", err)
+	}
+	return nil
 }
 
 // newT creates a new zero value of type T and returns it as a reflect.Value.
@@ -980,13 +1531,7 @@ func hasField(v any, name string) bool {
 	default:
 		t = reflect.TypeOf(v)
 	}
-	for t != nil && t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	if t.Kind() != reflect.Struct {
-		return false
-	}
-	_, ok := t.FieldByName(name)
+	_, ok := fieldByName(t, name)
 	return ok
 }
 
@@ -997,7 +1542,11 @@ func setField(v reflect.Value, name string, val any) {
 		}
 		v = v.Elem()
 	}
-	f := v.FieldByName(name)
+	sf, ok := fieldByName(v.Type(), name)
+	if !ok {
+		return
+	}
+	f := settableField(v, sf.Index)
 	if f.IsValid() && f.CanSet() {
 		rv := reflect.ValueOf(val)
 		if rv.Type().AssignableTo(f.Type()) {
@@ -1006,6 +1555,9 @@ func setField(v reflect.Value, name string, val any) {
 	}
 }
 
+// setNow stamps the named field with the current time. time.Time and
+// *time.Time fields receive time.Now(), integer fields unix seconds; other
+// types are left unchanged.
 func setNow(v reflect.Value, name string) {
 	for v.Kind() == reflect.Ptr {
 		if v.IsNil() {
@@ -1013,11 +1565,14 @@ func setNow(v reflect.Value, name string) {
 		}
 		v = v.Elem()
 	}
-	f := v.FieldByName(name)
+	sf, ok := fieldByName(v.Type(), name)
+	if !ok {
+		return
+	}
+	f := settableField(v, sf.Index)
 	if f.IsValid() && f.CanSet() {
-		switch f.Interface().(type) {
-		case time.Time:
-			f.Set(reflect.ValueOf(time.Now()))
+		if now, ok := nowValue(f.Type(), time.Now()); ok {
+			f.Set(reflect.ValueOf(now))
 		}
 	}
 }
@@ -14,7 +14,68 @@ import (
 
 type mockDialect struct{}
 
-func (mockDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+func (mockDialect) Placeholder(n int) string      { return fmt.Sprintf("$%d", n) }
+func (mockDialect) QuoteIdent(name string) string { return QuoteIdentWith(name, '"') }
+func (mockDialect) SupportsReturning() bool       { return true }
+func (mockDialect) LimitOffset(limit, offset string) string {
+	var clause string
+	if limit != "" {
+		clause += " LIMIT " + limit
+	}
+	if offset != "" {
+		clause += " OFFSET " + offset
+	}
+	return clause
+}
+func (d mockDialect) OnConflict(conflictCols, updateCols []string) string {
+	target := make([]string, len(conflictCols))
+	for i, c := range conflictCols {
+		target[i] = d.QuoteIdent(c)
+	}
+	if len(updateCols) == 0 {
+		return " ON CONFLICT (" + strings.Join(target, ",") + ") DO NOTHING"
+	}
+	sets := make([]string, len(updateCols))
+	for i, c := range updateCols {
+		q := d.QuoteIdent(c)
+		sets[i] = q + " = EXCLUDED." + q
+	}
+	return " ON CONFLICT (" + strings.Join(target, ",") + ") DO UPDATE SET " + strings.Join(sets, ",")
+}
+
+// mockPositionalDialect mimics MySQL: "?" placeholders, backtick quoting and
+// no RETURNING support.
+type mockPositionalDialect struct{}
+
+func (mockPositionalDialect) Placeholder(int) string        { return "?" }
+func (mockPositionalDialect) QuoteIdent(name string) string { return QuoteIdentWith(name, '`') }
+func (mockPositionalDialect) SupportsReturning() bool       { return false }
+func (mockPositionalDialect) LimitOffset(limit, offset string) string {
+	if limit == "" && offset == "" {
+		return ""
+	}
+	if limit == "" {
+		limit = "18446744073709551615"
+	}
+	clause := " LIMIT " + limit
+	if offset != "" {
+		clause += " OFFSET " + offset
+	}
+	return clause
+}
+func (mockPositionalDialect) OnConflict(conflictCols, updateCols []string) string {
+	if len(updateCols) == 0 {
+		if len(conflictCols) == 0 {
+			return ""
+		}
+		return " ON DUPLICATE KEY UPDATE `" + conflictCols[0] + "` = `" + conflictCols[0] + "`"
+	}
+	sets := make([]string, len(updateCols))
+	for i, c := range updateCols {
+		sets[i] = "`" + c + "` = VALUES(`" + c + "`)"
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+}
 
 type mockRows struct {
 	records [][]any
@@ -74,15 +135,32 @@ type mockResult struct{ rowsAffected int64 }
 
 func (m *mockResult) RowsAffected() int64 { return m.rowsAffected }
 
+// mockInsertIDResult additionally exposes LastInsertId, like a MySQL driver.
+type mockInsertIDResult struct {
+	mockResult
+	id int64
+}
+
+func (m *mockInsertIDResult) LastInsertId() (int64, error) { return m.id, nil }
+
 type mockQuerier struct {
 	queryRows  Rows
 	queryRow   Row
 	queryErr   error
 	execResult Result
 	execErr    error
+	lastSQL    string
+	lastArgs   []any
+	execCount  int
+}
+
+func (m *mockQuerier) record(sql string, args []any) {
+	m.lastSQL = sql
+	m.lastArgs = args
 }
 
 func (m *mockQuerier) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	m.record(sql, args)
 	if m.queryErr != nil {
 		return nil, m.queryErr
 	}
@@ -90,10 +168,13 @@ func (m *mockQuerier) Query(ctx context.Context, sql string, args ...any) (Rows,
 }
 
 func (m *mockQuerier) QueryRow(ctx context.Context, sql string, args ...any) Row {
+	m.record(sql, args)
 	return m.queryRow
 }
 
 func (m *mockQuerier) Exec(ctx context.Context, sql string, args ...any) (Result, error) {
+	m.record(sql, args)
+	m.execCount++
 	if m.execErr != nil {
 		return nil, m.execErr
 	}
@@ -236,7 +317,7 @@ func (p *pointerTable) TableName() string { return "pointer_table" }
 // ============================================
 
 func TestBuildPredicateNil(t *testing.T) {
-	clause, args := buildPredicate(nil, mockDialect{})
+	clause, args, _ := buildPredicate(nil, mockDialect{})
 	if clause != "" {
 		t.Errorf("expected empty clause for nil predicate, got %q", clause)
 	}
@@ -246,7 +327,7 @@ func TestBuildPredicateNil(t *testing.T) {
 }
 
 func TestPredicateEq(t *testing.T) {
-	clause, args := buildPredicate(Eq("name", "test"), mockDialect{})
+	clause, args, _ := buildPredicate(Eq("name", "test"), mockDialect{})
 	if clause != "name = $1" {
 		t.Errorf("expected 'name = $1', got %q", clause)
 	}
@@ -256,7 +337,7 @@ func TestPredicateEq(t *testing.T) {
 }
 
 func TestPredicateNe(t *testing.T) {
-	clause, args := buildPredicate(Ne("status", "deleted"), mockDialect{})
+	clause, args, _ := buildPredicate(Ne("status", "deleted"), mockDialect{})
 	if !strings.Contains(clause, "!=") {
 		t.Errorf("expected != in clause, got %q", clause)
 	}
@@ -266,7 +347,7 @@ func TestPredicateNe(t *testing.T) {
 }
 
 func TestPredicateGt(t *testing.T) {
-	clause, args := buildPredicate(Gt("age", 18), mockDialect{})
+	clause, args, _ := buildPredicate(Gt("age", 18), mockDialect{})
 	if !strings.Contains(clause, ">") {
 		t.Errorf("expected > in clause, got %q", clause)
 	}
@@ -276,7 +357,7 @@ func TestPredicateGt(t *testing.T) {
 }
 
 func TestPredicateGte(t *testing.T) {
-	clause, args := buildPredicate(Gte("score", 60), mockDialect{})
+	clause, args, _ := buildPredicate(Gte("score", 60), mockDialect{})
 	if !strings.Contains(clause, ">=") {
 		t.Errorf("expected >= in clause, got %q", clause)
 	}
@@ -286,7 +367,7 @@ func TestPredicateGte(t *testing.T) {
 }
 
 func TestPredicateLt(t *testing.T) {
-	clause, args := buildPredicate(Lt("count", 100), mockDialect{})
+	clause, args, _ := buildPredicate(Lt("count", 100), mockDialect{})
 	if !strings.Contains(clause, "<") {
 		t.Errorf("expected < in clause, got %q", clause)
 	}
@@ -296,7 +377,7 @@ func TestPredicateLt(t *testing.T) {
 }
 
 func TestPredicateLte(t *testing.T) {
-	clause, args := buildPredicate(Lte("limit", 50), mockDialect{})
+	clause, args, _ := buildPredicate(Lte("limit", 50), mockDialect{})
 	if !strings.Contains(clause, "<=") {
 		t.Errorf("expected <= in clause, got %q", clause)
 	}
@@ -306,7 +387,7 @@ func TestPredicateLte(t *testing.T) {
 }
 
 func TestPredicateIn(t *testing.T) {
-	clause, args := buildPredicate(In("id", 1, 2, 3), mockDialect{})
+	clause, args, _ := buildPredicate(In("id", 1, 2, 3), mockDialect{})
 	if !strings.Contains(clause, "IN ($1, $2, $3)") {
 		t.Errorf("expected IN ($1, $2, $3), got %q", clause)
 	}
@@ -316,7 +397,7 @@ func TestPredicateIn(t *testing.T) {
 }
 
 func TestPredicateInEmpty(t *testing.T) {
-	clause, args := buildPredicate(In("id"), mockDialect{})
+	clause, args, _ := buildPredicate(In("id"), mockDialect{})
 	if clause != "FALSE" {
 		t.Errorf("expected FALSE for empty IN, got %q", clause)
 	}
@@ -326,7 +407,7 @@ func TestPredicateInEmpty(t *testing.T) {
 }
 
 func TestPredicateNotIn(t *testing.T) {
-	clause, args := buildPredicate(NotIn("id", 1, 2), mockDialect{})
+	clause, args, _ := buildPredicate(NotIn("id", 1, 2), mockDialect{})
 	if !strings.Contains(clause, "NOT IN ($1, $2)") {
 		t.Errorf("expected NOT IN ($1, $2), got %q", clause)
 	}
@@ -336,7 +417,7 @@ func TestPredicateNotIn(t *testing.T) {
 }
 
 func TestPredicateNotInEmpty(t *testing.T) {
-	clause, args := buildPredicate(NotIn("id"), mockDialect{})
+	clause, args, _ := buildPredicate(NotIn("id"), mockDialect{})
 	if clause != "TRUE" {
 		t.Errorf("expected TRUE for empty NOT IN, got %q", clause)
 	}
@@ -346,7 +427,7 @@ func TestPredicateNotInEmpty(t *testing.T) {
 }
 
 func TestPredicateLike(t *testing.T) {
-	clause, args := buildPredicate(Like("name", "%test%"), mockDialect{})
+	clause, args, _ := buildPredicate(Like("name", "%test%"), mockDialect{})
 	if !strings.Contains(clause, "LIKE $1") {
 		t.Errorf("expected LIKE $1, got %q", clause)
 	}
@@ -356,7 +437,7 @@ func TestPredicateLike(t *testing.T) {
 }
 
 func TestPredicateILike(t *testing.T) {
-	clause, args := buildPredicate(ILike("name", "%ALICE%"), mockDialect{})
+	clause, args, _ := buildPredicate(ILike("name", "%ALICE%"), mockDialect{})
 	if !strings.Contains(clause, "ILIKE $1") {
 		t.Errorf("expected ILIKE $1, got %q", clause)
 	}
@@ -366,7 +447,7 @@ func TestPredicateILike(t *testing.T) {
 }
 
 func TestPredicateIsNull(t *testing.T) {
-	clause, args := buildPredicate(IsNull("deleted_at"), mockDialect{})
+	clause, args, _ := buildPredicate(IsNull("deleted_at"), mockDialect{})
 	if clause != "deleted_at IS NULL" {
 		t.Errorf("expected 'deleted_at IS NULL', got %q", clause)
 	}
@@ -376,7 +457,7 @@ func TestPredicateIsNull(t *testing.T) {
 }
 
 func TestPredicateIsNotNull(t *testing.T) {
-	clause, args := buildPredicate(IsNotNull("email"), mockDialect{})
+	clause, args, _ := buildPredicate(IsNotNull("email"), mockDialect{})
 	if clause != "email IS NOT NULL" {
 		t.Errorf("expected 'email IS NOT NULL', got %q", clause)
 	}
@@ -386,7 +467,7 @@ func TestPredicateIsNotNull(t *testing.T) {
 }
 
 func TestPredicateBetween(t *testing.T) {
-	clause, args := buildPredicate(Between("created_at", "2024-01-01", "2024-12-31"), mockDialect{})
+	clause, args, _ := buildPredicate(Between("created_at", "2024-01-01", "2024-12-31"), mockDialect{})
 	if !strings.Contains(clause, "BETWEEN $1 AND $2") {
 		t.Errorf("expected BETWEEN $1 AND $2, got %q", clause)
 	}
@@ -402,8 +483,104 @@ func TestPredicateJSONField(t *testing.T) {
 	}
 }
 
+func TestPredicateArrayAndJSONPath(t *testing.T) {
+	tests := []struct {
+		pred Predicate
+		want string
+	}{
+		{Any("id", []int64{1, 2}), "id = ANY($1)"},
+		{Overlaps("tags", []string{"a"}), "tags && $1"},
+		{ArrayContains("tags", []string{"a", "b"}), "tags @> $1"},
+		{JSONPathExists("payload", "$.items[*]"), "payload @? $1"},
+		{JSONPathMatch("payload", "$.total > 100"), "payload @@ $1"},
+	}
+	for _, tt := range tests {
+		clause, args, _ := buildPredicate(tt.pred, mockDialect{})
+		if clause != tt.want {
+			t.Errorf("got %q, want %q", clause, tt.want)
+		}
+		if len(args) != 1 {
+			t.Errorf("%s: expected 1 arg, got %d", tt.want, len(args))
+		}
+	}
+}
+
+func TestPredicateRaw(t *testing.T) {
+	pred := And(Eq("age", 1), Raw("lower(email) = ? OR email = ?", "a@b.c", "A@B.C"))
+	clause, args, _ := buildPredicate(pred, mockDialect{})
+	if clause != "(age = $1 AND (lower(email) = $2 OR email = $3))" {
+		t.Errorf("unexpected clause: %s", clause)
+	}
+	if !reflect.DeepEqual(args, []any{1, "a@b.c", "A@B.C"}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	raw := Raw(`tags ?? ? AND note <> 'why?' AND "odd?col" = ?`, "x", 2)
+	clause, args, _ = buildPredicate(raw, mockDialect{})
+	if clause != `(tags ? $1 AND note <> 'why?' AND "odd?col" = $2)` {
+		t.Errorf("unexpected clause: %s", clause)
+	}
+	if !reflect.DeepEqual(args, []any{"x", 2}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	// With ? placeholders the unescaped ?? is indistinguishable from one,
+	// which is why the escape is documented as PostgreSQL-only.
+	clause, args, _ = buildPredicate(raw, mockPositionalDialect{})
+	if clause != `(tags ? ? AND note <> 'why?' AND "odd?col" = ?)` {
+		t.Errorf("unexpected clause: %s", clause)
+	}
+	if len(args) != 2 {
+		t.Errorf("expected 2 args, got %v", args)
+	}
+}
+
+func TestPredicateRawInUpdateWhere(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockDialect{})
+	if err := c.UpdateWhere(context.Background(), Raw("age > ?", 3), map[string]any{"name": "n"}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	if mock.lastSQL != `UPDATE "test_table" SET "name" = $1 WHERE (age > $2)` {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+func TestPredicateRawArgCountMismatch(t *testing.T) {
+	bad := Raw("a = ? AND b = ?", 1)
+	if _, _, err := buildPredicate(Or(Eq("c", 2), bad), mockDialect{}); !errors.Is(err, ErrRawArgCount) {
+		t.Errorf("buildPredicate: expected ErrRawArgCount, got %v", err)
+	}
+
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}, queryRows: &mockRows{}, queryRow: &mockRow{record: []any{int64(1)}}}
+	c := New[testTable](mock, nil, mockDialect{})
+	ctx := context.Background()
+	_, findErr := c.Find(ctx, WithWhere(bad))
+	_, countErr := c.Count(ctx, bad)
+	for name, err := range map[string]error{
+		"Find":        findErr,
+		"Count":       countErr,
+		"UpdateWhere": c.UpdateWhere(ctx, bad, map[string]any{"name": "n"}),
+		"DeleteWhere": c.DeleteWhere(ctx, bad),
+	} {
+		if !errors.Is(err, ErrRawArgCount) {
+			t.Errorf("%s: expected ErrRawArgCount, got %v", name, err)
+		}
+	}
+	if mock.lastSQL != "" {
+		t.Errorf("no query should run, got %s", mock.lastSQL)
+	}
+}
+
+func TestPredicateJSONFieldEscapesKey(t *testing.T) {
+	expr := JSONField("payload", "x' OR '1'='1")
+	if expr != "payload->>'x'' OR ''1''=''1'" {
+		t.Errorf("key not escaped: %q", expr)
+	}
+}
+
 func TestPredicateJSONContains(t *testing.T) {
-	clause, args := buildPredicate(JSONContains("metadata", map[string]any{"key": "val"}), mockDialect{})
+	clause, args, _ := buildPredicate(JSONContains("metadata", map[string]any{"key": "val"}), mockDialect{})
 	if !strings.Contains(clause, "@>") {
 		t.Errorf("expected @> in clause, got %q", clause)
 	}
@@ -413,7 +590,7 @@ func TestPredicateJSONContains(t *testing.T) {
 }
 
 func TestPredicateAnd(t *testing.T) {
-	clause, args := buildPredicate(And(
+	clause, args, _ := buildPredicate(And(
 		Eq("status", "active"),
 		Eq("type", "admin"),
 	), mockDialect{})
@@ -426,7 +603,7 @@ func TestPredicateAnd(t *testing.T) {
 }
 
 func TestPredicateAndEmpty(t *testing.T) {
-	clause, args := buildPredicate(And(), mockDialect{})
+	clause, args, _ := buildPredicate(And(), mockDialect{})
 	if clause != "" {
 		t.Errorf("expected empty clause, got %q", clause)
 	}
@@ -436,7 +613,7 @@ func TestPredicateAndEmpty(t *testing.T) {
 }
 
 func TestPredicateAndNils(t *testing.T) {
-	clause, args := buildPredicate(And(nil, Eq("x", 1), nil), mockDialect{})
+	clause, args, _ := buildPredicate(And(nil, Eq("x", 1), nil), mockDialect{})
 	if !strings.Contains(clause, "x = $1") {
 		t.Errorf("expected 'x = $1' in clause, got %q", clause)
 	}
@@ -446,7 +623,7 @@ func TestPredicateAndNils(t *testing.T) {
 }
 
 func TestPredicateOr(t *testing.T) {
-	clause, args := buildPredicate(Or(
+	clause, args, _ := buildPredicate(Or(
 		Eq("status", "active"),
 		Eq("status", "pending"),
 	), mockDialect{})
@@ -459,7 +636,7 @@ func TestPredicateOr(t *testing.T) {
 }
 
 func TestPredicateOrEmpty(t *testing.T) {
-	clause, args := buildPredicate(Or(), mockDialect{})
+	clause, args, _ := buildPredicate(Or(), mockDialect{})
 	if clause != "" {
 		t.Errorf("expected empty clause, got %q", clause)
 	}
@@ -469,7 +646,7 @@ func TestPredicateOrEmpty(t *testing.T) {
 }
 
 func TestPredicateNot(t *testing.T) {
-	clause, args := buildPredicate(Not(Eq("deleted", true)), mockDialect{})
+	clause, args, _ := buildPredicate(Not(Eq("deleted", true)), mockDialect{})
 	if !strings.Contains(clause, "NOT (") {
 		t.Errorf("expected NOT (...) in clause, got %q", clause)
 	}
@@ -479,7 +656,7 @@ func TestPredicateNot(t *testing.T) {
 }
 
 func TestPredicateNotNil(t *testing.T) {
-	clause, args := buildPredicate(Not(nil), mockDialect{})
+	clause, args, _ := buildPredicate(Not(nil), mockDialect{})
 	if clause != "" {
 		t.Errorf("expected empty clause for Not(nil), got %q", clause)
 	}
@@ -490,7 +667,7 @@ func TestPredicateNotNil(t *testing.T) {
 
 func TestPredicateComplexOr(t *testing.T) {
 	// name ILIKE '%test%' OR label ILIKE '%test%' OR description ILIKE '%test%'
-	clause, args := buildPredicate(Or(
+	clause, args, _ := buildPredicate(Or(
 		ILike("name", "%test%"),
 		ILike("label", "%test%"),
 		ILike("description", "%test%"),
@@ -505,7 +682,7 @@ func TestPredicateComplexOr(t *testing.T) {
 
 func TestPredicateNestedAndOr(t *testing.T) {
 	// status = 'active' AND (name ILIKE '%a%' OR name ILIKE '%b%')
-	clause, args := buildPredicate(And(
+	clause, args, _ := buildPredicate(And(
 		Eq("status", "active"),
 		Or(
 			ILike("name", "%a%"),
@@ -521,7 +698,7 @@ func TestPredicateNestedAndOr(t *testing.T) {
 }
 
 func TestMapWhere(t *testing.T) {
-	clause, args := buildPredicate(MapWhere(map[string]any{
+	clause, args, _ := buildPredicate(MapWhere(map[string]any{
 		"name": "test",
 		"age":  30,
 	}), mockDialect{})
@@ -534,7 +711,7 @@ func TestMapWhere(t *testing.T) {
 }
 
 func TestMapWhereNilValue(t *testing.T) {
-	clause, args := buildPredicate(MapWhere(map[string]any{
+	clause, args, _ := buildPredicate(MapWhere(map[string]any{
 		"deleted_date": nil,
 	}), mockDialect{})
 	if !strings.Contains(clause, "IS NULL") {
@@ -546,7 +723,7 @@ func TestMapWhereNilValue(t *testing.T) {
 }
 
 func TestMapWhereSlice(t *testing.T) {
-	clause, args := buildPredicate(MapWhere(map[string]any{
+	clause, args, _ := buildPredicate(MapWhere(map[string]any{
 		"id": []any{1, 2, 3},
 	}), mockDialect{})
 	if !strings.Contains(clause, "= ANY") {
@@ -768,7 +945,7 @@ func TestScanTargets(t *testing.T) {
 	fm := defaultFieldMapper{}
 	row := testTable{}
 	v := reflect.ValueOf(&row).Elem()
-	targets, fields := scanTargets(v, fm)
+	targets, fields, columns := scanTargets(v, fm)
 
 	if len(targets) != 5 {
 		t.Fatalf("expected 5 targets, got %d", len(targets))
@@ -776,6 +953,16 @@ func TestScanTargets(t *testing.T) {
 	if len(fields) != 5 {
 		t.Fatalf("expected 5 fields, got %d", len(fields))
 	}
+	if want := []string{"id", "name", "age", "created_date", "deleted_date"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %v, want %v", columns, want)
+	}
+
+	// Unaddressable fields are skipped, and their columns with them, so the
+	// columns stay aligned with the fields for the decoder.
+	_, fields, columns = scanTargets(reflect.ValueOf(row), fm)
+	if len(fields) != 0 || len(columns) != 0 {
+		t.Errorf("expected no targets for an unaddressable row, got %d fields, columns %v", len(fields), columns)
+	}
 	for i, target := range targets {
 		if _, ok := target.(*any); !ok {
 			t.Errorf("target[%d] should be *any", i)
@@ -786,7 +973,7 @@ func TestScanTargets(t *testing.T) {
 func TestNullSafeCopyString(t *testing.T) {
 	type s struct{ Name string }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = "hello"
 	targets := []any{&dest}
@@ -800,7 +987,7 @@ func TestNullSafeCopyString(t *testing.T) {
 func TestNullSafeCopyNil(t *testing.T) {
 	type s struct{ Name string }
 	v := reflect.ValueOf(&s{Name: "original"}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = nil
 	targets := []any{&dest}
@@ -814,7 +1001,7 @@ func TestNullSafeCopyNil(t *testing.T) {
 func TestNullSafeCopyInt(t *testing.T) {
 	type s struct{ Age int }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = float64(42)
 	targets := []any{&dest}
@@ -828,7 +1015,7 @@ func TestNullSafeCopyInt(t *testing.T) {
 func TestNullSafeCopyFloat(t *testing.T) {
 	type s struct{ Score float64 }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = int64(95)
 	targets := []any{&dest}
@@ -842,7 +1029,7 @@ func TestNullSafeCopyFloat(t *testing.T) {
 func TestNullSafeCopyBool(t *testing.T) {
 	type s struct{ Active bool }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = true
 	targets := []any{&dest}
@@ -1108,7 +1295,7 @@ func TestCurdUpdateWhere(t *testing.T) {
 	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 2}}
 	c := New[testTable](mock, nil, mockDialect{})
 
-	err := c.UpdateWhere(context.Background(), Eq("status", "old"), map[string]any{"status": "new"})
+	err := c.UpdateWhere(context.Background(), Eq("name", "old"), map[string]any{"name": "new"})
 	if err != nil {
 		t.Fatalf("UpdateWhere error: %v", err)
 	}
@@ -1568,7 +1755,7 @@ func TestHasFieldDoublePointer(t *testing.T) {
 func TestNullSafeCopyIntDefault(t *testing.T) {
 	type s struct{ Age int }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = "not_a_number"
 	targets := []any{&dest}
@@ -1582,7 +1769,7 @@ func TestNullSafeCopyIntDefault(t *testing.T) {
 func TestNullSafeCopyFloatDefault(t *testing.T) {
 	type s struct{ Score float64 }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = "not_a_float"
 	targets := []any{&dest}
@@ -1596,7 +1783,7 @@ func TestNullSafeCopyFloatDefault(t *testing.T) {
 func TestNullSafeCopyBoolDefault(t *testing.T) {
 	type s struct{ Active bool }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = "not_a_bool"
 	targets := []any{&dest}
@@ -1610,7 +1797,7 @@ func TestNullSafeCopyBoolDefault(t *testing.T) {
 func TestNullSafeCopyAssignable(t *testing.T) {
 	type s struct{ Name string }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = "direct"
 	targets := []any{&dest}
@@ -1624,7 +1811,7 @@ func TestNullSafeCopyAssignable(t *testing.T) {
 func TestNullSafeCopyIntFromInt(t *testing.T) {
 	type s struct{ Count int }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = int64(100)
 	targets := []any{&dest}
@@ -1638,7 +1825,7 @@ func TestNullSafeCopyIntFromInt(t *testing.T) {
 func TestNullSafeCopyFloatFromFloat(t *testing.T) {
 	type s struct{ Rate float64 }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 
 	var dest any = float64(3.14)
 	targets := []any{&dest}
@@ -1652,7 +1839,7 @@ func TestNullSafeCopyFloatFromFloat(t *testing.T) {
 func TestNullSafeCopyUnsettableField(t *testing.T) {
 	type s struct{ name string }
 	v := reflect.ValueOf(&s{}).Elem()
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 	if len(fields) > 0 && !fields[0].CanSet() {
 		var dest any = "hello"
 		targets := []any{&dest}
@@ -1696,7 +1883,7 @@ func TestSetFieldPointer(t *testing.T) {
 func TestScanTargetsUnaddressable(t *testing.T) {
 	type s struct{ Name string }
 	v := reflect.ValueOf(s{})
-	_, fields := scanTargets(v, defaultFieldMapper{})
+	_, fields, _ := scanTargets(v, defaultFieldMapper{})
 	if len(fields) != 0 {
 		t.Error("unaddressable struct should produce no fields")
 	}
@@ -2368,7 +2555,7 @@ func TestScanTargetsPointer(t *testing.T) {
 	fm := defaultFieldMapper{}
 	row := &testTable{}
 	v := reflect.ValueOf(row)
-	targets, fields := scanTargets(v, fm)
+	targets, fields, _ := scanTargets(v, fm)
 
 	if len(targets) != 5 {
 		t.Fatalf("expected 5 targets, got %d", len(targets))
@@ -2741,3 +2928,3142 @@ func TestCurdEachFnError(t *testing.T) {
 	}
 }
 
+// ============================================
+// Dialect Tests
+// ============================================
+
+func TestQuoteIdentWith(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"users", `"users"`},
+		{"public.users", `"public"."users"`},
+		{"created_date", `"created_date"`},
+		{"count(*)", "count(*)"},
+		{"users AS u", "users AS u"},
+		{"1", "1"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := QuoteIdentWith(tt.in, '"'); got != tt.expect {
+			t.Errorf("QuoteIdentWith(%q) = %q, want %q", tt.in, got, tt.expect)
+		}
+	}
+}
+
+func TestFormatSQLPositional(t *testing.T) {
+	got := formatSQL("SELECT * FROM t WHERE a = ? AND b = ?", "x", 2)
+	want := "SELECT * FROM t WHERE a = 'x' AND b = 2"
+	if got != want {
+		t.Errorf("formatSQL = %q, want %q", got, want)
+	}
+}
+
+func TestCurdPositionalDialectFindAll(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	_, err := c.FindAll(context.Background(), Eq("name", "a"), "id ASC", 10, 20)
+	if err != nil {
+		t.Fatalf("FindAll error: %v", err)
+	}
+	want := "SELECT `id`,`name`,`age`,`created_date`,`deleted_date` FROM `test_table` WHERE name = ? AND `deleted_date` IS NULL ORDER BY `id` ASC LIMIT ? OFFSET ?"
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if len(mock.lastArgs) != 3 || mock.lastArgs[1] != 10 || mock.lastArgs[2] != 20 {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+}
+
+func TestCurdPositionalDialectOffsetOnly(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[noIDTable](mock, nil, mockPositionalDialect{})
+
+	if _, err := c.Find(context.Background(), WithOffset(5)); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, " LIMIT 18446744073709551615 OFFSET ?") {
+		t.Errorf("expected MySQL offset-only clause, got %s", mock.lastSQL)
+	}
+}
+
+func TestCurdPositionalDialectInsertOneLastInsertID(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockInsertIDResult{id: 77}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	row := &testTable{Name: "mysql", Age: 3}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if strings.Contains(mock.lastSQL, "RETURNING") {
+		t.Errorf("unexpected RETURNING in %s", mock.lastSQL)
+	}
+	if !strings.HasPrefix(mock.lastSQL, "INSERT INTO `test_table` (`name`,`age`,") {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+	if row.ID != 77 {
+		t.Errorf("expected ID 77 from LastInsertId, got %d", row.ID)
+	}
+}
+
+func TestCurdPositionalDialectUpdateWhere(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	err := c.UpdateWhere(context.Background(), Eq("age", 1), map[string]any{"name": "n"})
+	if err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	want := "UPDATE `test_table` SET `name` = ? WHERE age = ?"
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdPositionalDialectDeleteByID(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	if err := c.DeleteByID(context.Background(), 1, false); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+	want := "UPDATE `test_table` SET `deleted_date` = ? WHERE `id` = ? AND `deleted_date` IS NULL"
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+// ============================================
+// Native Upsert Tests
+// ============================================
+
+func TestCurdUpsertOnConflict(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{int64(9)}}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	row := &testTable{Name: "a", Age: 1}
+	if err := c.UpsertOnConflict(context.Background(), row, []string{"name"}, []string{"age"}); err != nil {
+		t.Fatalf("UpsertOnConflict error: %v", err)
+	}
+	want := `INSERT INTO "test_table" ("name","age","created_date","deleted_date") VALUES ($1,$2,$3,$4)` +
+		` ON CONFLICT ("name") DO UPDATE SET "age" = EXCLUDED."age" RETURNING "id"`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if row.ID != 9 {
+		t.Errorf("expected ID 9, got %d", row.ID)
+	}
+}
+
+func TestCurdUpsertOnConflictDefaultColumns(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{int64(1)}}}
+	c := New[testTableWithTime](mock, nil, mockDialect{})
+
+	row := &testTableWithTime{}
+	if err := c.UpsertOnConflict(context.Background(), row, []string{"id"}, nil); err != nil {
+		t.Fatalf("UpsertOnConflict error: %v", err)
+	}
+	if !strings.Contains(mock.lastSQL, `DO UPDATE SET "changed_date" = EXCLUDED."changed_date","deleted_date" = EXCLUDED."deleted_date" RETURNING`) {
+		t.Errorf("unexpected default update columns: %s", mock.lastSQL)
+	}
+	if strings.Contains(mock.lastSQL, `"created_date" = EXCLUDED`) {
+		t.Errorf("created_date must not be overwritten: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdUpsertOnConflictIncrementsVersion(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{int64(1)}}, execResult: &mockResult{rowsAffected: 2}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+	ctx := context.Background()
+
+	want := `INSERT INTO "versioned" ("name","version") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET` +
+		` "name" = EXCLUDED."name","version" = "versioned"."version" + 1 RETURNING "id"`
+	// By default and when listed, version is incremented, not overwritten.
+	for _, updateCols := range [][]string{nil, {"name", "version"}} {
+		if err := c.UpsertOnConflict(ctx, &versionedTable{Name: "a", Version: 7}, []string{"id"}, updateCols); err != nil {
+			t.Fatalf("UpsertOnConflict error: %v", err)
+		}
+		if mock.lastSQL != want {
+			t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+		}
+	}
+
+	rows := []versionedTable{{Name: "a"}, {Name: "b"}}
+	if err := c.UpsertBatchOnConflict(ctx, rows, []string{"id"}, nil); err != nil {
+		t.Fatalf("UpsertBatchOnConflict error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, `DO UPDATE SET "name" = EXCLUDED."name","version" = "versioned"."version" + 1`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	err := c.UpsertOnConflict(ctx, &versionedTable{Name: "a"}, []string{"name"}, []string{"version"})
+	if err == nil {
+		t.Error("expected an error when only the version column is listed")
+	}
+}
+
+func TestCurdUpsertOnConflictNoUpdateColumns(t *testing.T) {
+	c := New[noIDTable](&mockQuerier{}, nil, mockDialect{})
+	err := c.UpsertOnConflict(context.Background(), &noIDTable{Name: "x"}, []string{"name"}, nil)
+	if err == nil {
+		t.Error("expected error when nothing can be updated")
+	}
+}
+
+func TestCurdUpsertOnConflictPositional(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockInsertIDResult{id: 5}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	row := &testTable{Name: "a"}
+	if err := c.UpsertOnConflict(context.Background(), row, []string{"name"}, []string{"age"}); err != nil {
+		t.Fatalf("UpsertOnConflict error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, "ON DUPLICATE KEY UPDATE `age` = VALUES(`age`)") {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+	if row.ID != 5 {
+		t.Errorf("expected ID 5, got %d", row.ID)
+	}
+}
+
+func TestCurdInsertIgnoreNoConflictColumnsPositional(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockInsertIDResult{mockResult: mockResult{rowsAffected: 1}, id: 4}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	if _, err := c.InsertIgnore(context.Background(), &testTable{Name: "a"}, nil); err != nil {
+		t.Fatalf("InsertIgnore error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, "ON DUPLICATE KEY UPDATE `id` = `id`") {
+		t.Errorf("expected the primary key assigned to itself: %s", mock.lastSQL)
+	}
+	if _, err := c.InsertIgnoreBatch(context.Background(), []testTable{{Name: "a"}}, nil); err != nil {
+		t.Fatalf("InsertIgnoreBatch error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, "ON DUPLICATE KEY UPDATE `id` = `id`") {
+		t.Errorf("expected the primary key assigned to itself: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdInsertIgnore(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(3)}}}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	row := &testTable{Name: "a"}
+	inserted, err := c.InsertIgnore(context.Background(), row, []string{"name"})
+	if err != nil {
+		t.Fatalf("InsertIgnore error: %v", err)
+	}
+	if !inserted || row.ID != 3 {
+		t.Errorf("expected inserted with ID 3, got %v %d", inserted, row.ID)
+	}
+	if !strings.Contains(mock.lastSQL, `ON CONFLICT ("name") DO NOTHING RETURNING "id"`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdInsertIgnoreConflict(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	row := &testTable{Name: "a"}
+	inserted, err := c.InsertIgnore(context.Background(), row, []string{"name"})
+	if err != nil {
+		t.Fatalf("InsertIgnore error: %v", err)
+	}
+	if inserted || row.ID != 0 {
+		t.Errorf("expected no insert, got %v %d", inserted, row.ID)
+	}
+}
+
+func TestCurdInsertIgnoreNoID(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 0}}
+	c := New[noIDTable](mock, nil, mockDialect{})
+
+	inserted, err := c.InsertIgnore(context.Background(), &noIDTable{Name: "a"}, []string{"name"})
+	if err != nil {
+		t.Fatalf("InsertIgnore error: %v", err)
+	}
+	if inserted {
+		t.Error("expected no insert when no rows are affected")
+	}
+}
+
+func TestCurdUpsertBatchOnConflict(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 2}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	rows := []testTable{{Name: "a", Age: 1}, {Name: "b", Age: 2}}
+	if err := c.UpsertBatchOnConflict(context.Background(), rows, []string{"name"}, []string{"age"}); err != nil {
+		t.Fatalf("UpsertBatchOnConflict error: %v", err)
+	}
+	want := `INSERT INTO "test_table" ("name","age","created_date","deleted_date") VALUES ($1,$2,$3,$4),($5,$6,$7,$8)` +
+		` ON CONFLICT ("name") DO UPDATE SET "age" = EXCLUDED."age"`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if len(mock.lastArgs) != 8 {
+		t.Errorf("expected 8 args, got %d", len(mock.lastArgs))
+	}
+}
+
+func TestCurdInsertIgnoreBatch(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	n, err := c.InsertIgnoreBatch(context.Background(), []testTable{{Name: "a"}, {Name: "b"}}, []string{"name"})
+	if err != nil {
+		t.Fatalf("InsertIgnoreBatch error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 inserted, got %d", n)
+	}
+	if !strings.HasSuffix(mock.lastSQL, `ON CONFLICT ("name") DO NOTHING`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+// ============================================
+// Bulk Insert Tests
+// ============================================
+
+// mockCopier is a Querier that also implements BulkCopier.
+type mockCopier struct {
+	mockQuerier
+	copyTable string
+	copyCols  []string
+	copyRows  [][]any
+	copyErr   error
+}
+
+func (m *mockCopier) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	m.copyTable = table
+	m.copyCols = columns
+	m.copyRows = rows
+	return int64(len(rows)), m.copyErr
+}
+
+func TestChunkTuples(t *testing.T) {
+	tuples := make([][]any, 10)
+	chunks := chunkTuples(tuples, maxBindParams/3)
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks of 3, got %d", len(chunks))
+	}
+	if len(chunks[3]) != 1 {
+		t.Errorf("expected last chunk of 1, got %d", len(chunks[3]))
+	}
+	if got := chunkTuples(tuples, 0); len(got) != 1 {
+		t.Errorf("expected a single chunk for zero width, got %d", len(got))
+	}
+}
+
+func TestCurdInsertBatchChunksLargeBatches(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	// testTable inserts 4 columns, so 16383 rows fit in one statement.
+	rows := make([]testTable, 20000)
+	if err := c.InsertBatch(context.Background(), rows); err != nil {
+		t.Fatalf("InsertBatch error: %v", err)
+	}
+	if mock.execCount != 2 {
+		t.Errorf("expected 2 chunked statements, got %d", mock.execCount)
+	}
+	if len(mock.lastArgs) != (20000-maxBindParams/4)*4 {
+		t.Errorf("unexpected args in last chunk: %d", len(mock.lastArgs))
+	}
+	if !strings.Contains(mock.lastSQL, "VALUES ($1,$2,$3,$4),") {
+		t.Errorf("expected placeholders to restart per chunk: %.80s", mock.lastSQL)
+	}
+}
+
+func TestCurdUpsertBatchesChunkLargeBatches(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 3}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	// testTable inserts 4 columns, so 16383 rows fit in one statement.
+	rows := make([]testTable, 20000)
+	if err := c.UpsertBatchOnConflict(context.Background(), rows, []string{"name"}, []string{"age"}); err != nil {
+		t.Fatalf("UpsertBatchOnConflict error: %v", err)
+	}
+	if mock.execCount != 2 {
+		t.Errorf("expected 2 chunked upserts, got %d", mock.execCount)
+	}
+	if len(mock.lastArgs) != (20000-maxBindParams/4)*4 {
+		t.Errorf("unexpected args in last chunk: %d", len(mock.lastArgs))
+	}
+	if !strings.HasSuffix(mock.lastSQL, `ON CONFLICT ("name") DO UPDATE SET "age" = EXCLUDED."age"`) {
+		t.Errorf("expected every chunk to carry the conflict clause: %s", mock.lastSQL[len(mock.lastSQL)-80:])
+	}
+
+	mock.execCount = 0
+	n, err := c.InsertIgnoreBatch(context.Background(), rows, []string{"name"})
+	if err != nil {
+		t.Fatalf("InsertIgnoreBatch error: %v", err)
+	}
+	if mock.execCount != 2 || n != 6 {
+		t.Errorf("expected 2 chunked inserts affecting 6 rows, got %d inserts, %d rows", mock.execCount, n)
+	}
+	if !strings.HasSuffix(mock.lastSQL, `ON CONFLICT ("name") DO NOTHING`) {
+		t.Errorf("expected every chunk to carry the conflict clause: %s", mock.lastSQL[len(mock.lastSQL)-80:])
+	}
+}
+
+func TestCurdInsertBatchUsesBulkCopier(t *testing.T) {
+	mock := &mockCopier{}
+	c := New[testTableWithJSONB](mock, nil, mockDialect{}, WithCopyThreshold(2)).
+		WithTransformer(JSONBMarshaler("metadata"))
+
+	rows := []testTableWithJSONB{
+		{Metadata: map[string]any{"k": "v"}},
+		{Metadata: map[string]any{"k": "w"}},
+	}
+	if err := c.InsertBatch(context.Background(), rows); err != nil {
+		t.Fatalf("InsertBatch error: %v", err)
+	}
+	if mock.execCount != 0 {
+		t.Errorf("expected no INSERT statements, got %d", mock.execCount)
+	}
+	if mock.copyTable != "test_jsonb" || len(mock.copyRows) != 2 {
+		t.Fatalf("unexpected copy: %s %d", mock.copyTable, len(mock.copyRows))
+	}
+	if mock.copyRows[0][0] != `{"k":"v"}` {
+		t.Errorf("expected transformed metadata, got %v", mock.copyRows[0][0])
+	}
+}
+
+func TestCurdInsertBatchBelowCopyThreshold(t *testing.T) {
+	mock := &mockCopier{mockQuerier: mockQuerier{execResult: &mockResult{}}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if err := c.InsertBatch(context.Background(), []testTable{{Name: "a"}}); err != nil {
+		t.Fatalf("InsertBatch error: %v", err)
+	}
+	if mock.copyRows != nil || mock.execCount != 1 {
+		t.Errorf("expected INSERT below threshold, copy=%v exec=%d", mock.copyRows, mock.execCount)
+	}
+}
+
+func TestCurdInsertBatchCopyTimestamps(t *testing.T) {
+	mock := &mockCopier{}
+	c := New[testTableWithTime](mock, nil, mockDialect{}, WithCopyThreshold(1))
+
+	rows := []testTableWithTime{{}}
+	if err := c.InsertBatch(context.Background(), rows); err != nil {
+		t.Fatalf("InsertBatch error: %v", err)
+	}
+	if rows[0].CreatedDate.IsZero() || rows[0].ChangedDate.IsZero() {
+		t.Error("expected timestamps to be set on copied rows")
+	}
+}
+
+func TestCurdInsertBatchCopyError(t *testing.T) {
+	mock := &mockCopier{copyErr: errors.New("copy failed")}
+	c := New[testTable](mock, nil, mockDialect{}, WithCopyThreshold(1))
+
+	if err := c.InsertBatch(context.Background(), []testTable{{Name: "a"}}); err == nil {
+		t.Error("expected copy error")
+	}
+}
+
+// ============================================
+// Keyset Pagination Tests
+// ============================================
+
+func TestCurdFindAfterFirstPage(t *testing.T) {
+	mock := &mockQuerier{
+		queryRows: &mockRows{records: [][]any{
+			{int64(1), "a", int(1), "now", nil},
+			{int64(2), "b", int(2), "now", nil},
+			{int64(3), "c", int(3), "now", nil},
+		}},
+	}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	page, err := c.FindAfter(context.Background(), "", WithLimit(2))
+	if err != nil {
+		t.Fatalf("FindAfter error: %v", err)
+	}
+	if len(page.List) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(page.List))
+	}
+	if page.NextCursor == "" {
+		t.Fatal("expected next cursor")
+	}
+	if !strings.HasSuffix(mock.lastSQL, `ORDER BY "id" ASC LIMIT $1`) || mock.lastArgs[0] != 3 {
+		t.Errorf("unexpected SQL: %s %v", mock.lastSQL, mock.lastArgs)
+	}
+
+	values, err := decodeCursor(page.NextCursor, 1)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if values[0] != int64(2) {
+		t.Errorf("expected cursor on id 2, got %v (%T)", values[0], values[0])
+	}
+}
+
+func TestCurdFindAfterLastPage(t *testing.T) {
+	mock := &mockQuerier{
+		queryRows: &mockRows{records: [][]any{{int64(3), "c", int(3), "now", nil}}},
+	}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	cursor, _ := encodeCursor([]any{int64(2)})
+	page, err := c.FindAfter(context.Background(), cursor, WithWhere(Eq("age", 3)), WithLimit(2))
+	if err != nil {
+		t.Fatalf("FindAfter error: %v", err)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("expected no next cursor, got %q", page.NextCursor)
+	}
+	want := `WHERE (age = $1 AND (("id" > $2))) AND "deleted_date" IS NULL ORDER BY "id" ASC LIMIT $3`
+	if !strings.HasSuffix(mock.lastSQL, want) {
+		t.Errorf("unexpected SQL:\n got: %s\nwant suffix: %s", mock.lastSQL, want)
+	}
+	if mock.lastArgs[1] != int64(2) {
+		t.Errorf("expected keyset arg 2, got %v", mock.lastArgs[1])
+	}
+}
+
+func TestCurdFindAfterMixedDirections(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	cursor, _ := encodeCursor([]any{"bob", int64(7)})
+	_, err := c.FindAfter(context.Background(), cursor, WithOrder(Desc("name"), Asc("id")))
+	if err != nil {
+		t.Fatalf("FindAfter error: %v", err)
+	}
+	want := `WHERE (("name" < $1) OR ("name" = $2 AND "id" > $3)) AND "deleted_date" IS NULL ORDER BY "name" DESC, "id" ASC LIMIT $4`
+	if !strings.HasSuffix(mock.lastSQL, want) {
+		t.Errorf("unexpected SQL:\n got: %s\nwant suffix: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdFindAfterInvalidCursor(t *testing.T) {
+	c := New[testTable](&mockQuerier{}, nil, mockDialect{})
+
+	if _, err := c.FindAfter(context.Background(), "!!not-base64"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+	cursor, _ := encodeCursor([]any{1, 2})
+	if _, err := c.FindAfter(context.Background(), cursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for key length mismatch, got %v", err)
+	}
+}
+
+func TestCurdFindAfterUnknownSortColumn(t *testing.T) {
+	mock := &mockQuerier{
+		queryRows: &mockRows{records: [][]any{
+			{int64(1), "a", int(1), "now", nil},
+			{int64(2), "b", int(2), "now", nil},
+		}},
+	}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	_, err := c.FindAfter(context.Background(), "", WithOrder(Asc("missing")), WithLimit(1))
+	if err == nil {
+		t.Error("expected error for sort column without a field")
+	}
+}
+
+// keysetQuerier records every Query and serves batches in order.
+type keysetQuerier struct {
+	eachQuerier
+	sqls []string
+	args [][]any
+}
+
+func (k *keysetQuerier) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	k.sqls = append(k.sqls, sql)
+	k.args = append(k.args, args)
+	return k.eachQuerier.Query(ctx, sql, args...)
+}
+
+func TestCurdEachUsesKeyset(t *testing.T) {
+	kq := &keysetQuerier{eachQuerier: eachQuerier{batches: []*mockRows{
+		{records: [][]any{{int64(1), "a", int(1), "now", nil}, {int64(2), "b", int(2), "now", nil}}},
+		{records: [][]any{{int64(5), "c", int(3), "now", nil}}},
+	}}}
+	c := New[testTable](kq, nil, mockDialect{}, WithEachKey(Asc("name"), Asc("id")))
+
+	err := c.Each(context.Background(), nil, 2, func([]testTable) error { return nil })
+	if err != nil {
+		t.Fatalf("Each error: %v", err)
+	}
+	if len(kq.sqls) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(kq.sqls))
+	}
+	if strings.Contains(kq.sqls[1], "OFFSET") {
+		t.Errorf("expected keyset iteration without OFFSET: %s", kq.sqls[1])
+	}
+	if !strings.Contains(kq.sqls[1], `(("name" > $1) OR ("name" = $2 AND "id" > $3))`) {
+		t.Errorf("unexpected keyset SQL: %s", kq.sqls[1])
+	}
+	if kq.args[1][0] != "b" || kq.args[1][2] != int64(2) {
+		t.Errorf("unexpected keyset args: %v", kq.args[1])
+	}
+}
+
+// ============================================
+// Iterator Tests
+// ============================================
+
+func TestCurdIter(t *testing.T) {
+	rows := &mockRows{records: [][]any{
+		{int64(1), "a", int(1), "now", nil},
+		{int64(2), "b", int(2), "now", nil},
+	}}
+	mock := &mockQuerier{queryRows: rows}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	var names []string
+	for row, err := range c.Iter(context.Background(), WithWhere(Eq("age", 1))) {
+		if err != nil {
+			t.Fatalf("Iter error: %v", err)
+		}
+		names = append(names, row.Name)
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("unexpected rows: %v", names)
+	}
+	if !rows.closed {
+		t.Error("expected rows to be closed")
+	}
+	if !strings.Contains(mock.lastSQL, "WHERE age = $1") {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdIterEarlyBreakClosesRows(t *testing.T) {
+	rows := &mockRows{records: [][]any{
+		{int64(1), "a", int(1), "now", nil},
+		{int64(2), "b", int(2), "now", nil},
+	}}
+	c := New[testTable](&mockQuerier{queryRows: rows}, nil, mockDialect{})
+
+	n := 0
+	for range c.Iter(context.Background()) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("expected 1 iteration, got %d", n)
+	}
+	if !rows.closed {
+		t.Error("expected rows to be closed on break")
+	}
+	if rows.pos != 1 {
+		t.Errorf("expected iteration to stop after first row, pos=%d", rows.pos)
+	}
+}
+
+func TestCurdIterContextCancelled(t *testing.T) {
+	rows := &mockRows{records: [][]any{{int64(1), "a", int(1), "now", nil}}}
+	c := New[testTable](&mockQuerier{queryRows: rows}, nil, mockDialect{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var gotErr error
+	for _, err := range c.Iter(ctx) {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", gotErr)
+	}
+	if !rows.closed {
+		t.Error("expected rows to be closed")
+	}
+}
+
+func TestCurdIterQueryError(t *testing.T) {
+	c := New[testTable](&mockQuerier{queryErr: errors.New("boom")}, nil, mockDialect{})
+
+	n := 0
+	for _, err := range c.Iter(context.Background()) {
+		n++
+		if err == nil {
+			t.Error("expected query error")
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected a single error element, got %d", n)
+	}
+}
+
+func TestCurdIterRowsErr(t *testing.T) {
+	rows := &mockRows{err: errors.New("conn reset")}
+	c := New[testTable](&mockQuerier{queryRows: rows}, nil, mockDialect{})
+
+	var gotErr error
+	for _, err := range c.Iter(context.Background()) {
+		gotErr = err
+	}
+	if gotErr == nil || gotErr.Error() != "conn reset" {
+		t.Errorf("expected rows error, got %v", gotErr)
+	}
+}
+
+func TestQueryIter(t *testing.T) {
+	type row struct {
+		Value int `json:"value"`
+	}
+	rows := &mockRows{records: [][]any{{int(7)}, {int(8)}}}
+	mock := &mockQuerier{queryRows: rows}
+
+	var sum int
+	for r, err := range QueryIter[row](context.Background(), mock, "SELECT value FROM t") {
+		if err != nil {
+			t.Fatalf("QueryIter error: %v", err)
+		}
+		sum += r.Value
+	}
+	if sum != 15 {
+		t.Errorf("expected sum 15, got %d", sum)
+	}
+	if !rows.closed {
+		t.Error("expected rows to be closed")
+	}
+}
+
+// ============================================
+// Primary Key Tests
+// ============================================
+
+type uuidKeyTable struct {
+	UUID string `json:"uuid" curd:"pk"`
+	Name string `json:"name"`
+}
+
+func (uuidKeyTable) TableName() string { return "uuid_table" }
+
+type compositeKeyTable struct {
+	TenantID int64  `json:"tenant_id" curd:"pk"`
+	Code     string `json:"code" curd:"pk"`
+	Label    string `json:"label"`
+}
+
+func (compositeKeyTable) TableName() string { return "composite_table" }
+
+func TestPrimaryKeyFields(t *testing.T) {
+	fm := defaultFieldMapper{}
+	tests := []struct {
+		typ  reflect.Type
+		cols []string
+	}{
+		{reflect.TypeOf(testTable{}), []string{"id"}},
+		{reflect.TypeOf(testTableGorm{}), []string{"gid"}},
+		{reflect.TypeOf(uuidKeyTable{}), []string{"uuid"}},
+		{reflect.TypeOf(&compositeKeyTable{}), []string{"tenant_id", "code"}},
+		{reflect.TypeOf(noIDTable{}), nil},
+	}
+	for _, tt := range tests {
+		pk := primaryKeyFields(tt.typ, fm)
+		var cols []string
+		for _, p := range pk {
+			cols = append(cols, p.column)
+		}
+		if strings.Join(cols, ",") != strings.Join(tt.cols, ",") {
+			t.Errorf("%v: expected pk %v, got %v", tt.typ, tt.cols, cols)
+		}
+	}
+}
+
+func TestCurdFindByIDStringKey(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{"abc", "x"}}}}
+	c := New[uuidKeyTable](mock, nil, mockDialect{})
+
+	row, err := c.FindByID(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("FindByID error: %v", err)
+	}
+	if row.UUID != "abc" {
+		t.Errorf("expected uuid abc, got %q", row.UUID)
+	}
+	if !strings.Contains(mock.lastSQL, `WHERE "uuid" = $1`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdInsertOneStringKeyReturning(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{"generated-uuid"}}}
+	c := New[uuidKeyTable](mock, nil, mockDialect{})
+
+	row := &uuidKeyTable{Name: "n"}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	want := `INSERT INTO "uuid_table" ("name") VALUES ($1) RETURNING "uuid"`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if row.UUID != "generated-uuid" {
+		t.Errorf("expected generated uuid, got %q", row.UUID)
+	}
+}
+
+func TestCurdInsertOneClientKey(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{"client-uuid"}}}
+	c := New[uuidKeyTable](mock, nil, mockDialect{})
+
+	row := &uuidKeyTable{UUID: "client-uuid", Name: "n"}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if !strings.HasPrefix(mock.lastSQL, `INSERT INTO "uuid_table" ("uuid","name") VALUES ($1,$2)`) {
+		t.Errorf("expected client key to be inserted: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdCompositeKeyFindByID(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(1), "A", "l"}}}}
+	c := New[compositeKeyTable](mock, nil, mockDialect{})
+
+	row, err := c.FindByID(context.Background(), int64(1), "A")
+	if err != nil {
+		t.Fatalf("FindByID error: %v", err)
+	}
+	if row.Code != "A" {
+		t.Errorf("unexpected row: %+v", row)
+	}
+	if !strings.Contains(mock.lastSQL, `WHERE ("tenant_id" = $1 AND "code" = $2)`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdCompositeKeyUpdateByID(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[compositeKeyTable](mock, nil, mockDialect{})
+
+	err := c.UpdateByID(context.Background(), PK(int64(1), "A"), map[string]any{"label": "new"})
+	if err != nil {
+		t.Fatalf("UpdateByID error: %v", err)
+	}
+	want := `UPDATE "composite_table" SET "label" = $1 WHERE ("tenant_id" = $2 AND "code" = $3)`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdCompositeKeyDeleteByIDStruct(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[compositeKeyTable](mock, nil, mockDialect{})
+
+	key := struct {
+		TenantID int64
+		Code     string
+	}{7, "B"}
+	if err := c.DeleteByID(context.Background(), key, true); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+	want := `DELETE FROM "composite_table" WHERE ("tenant_id" = $1 AND "code" = $2)`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if mock.lastArgs[0] != int64(7) || mock.lastArgs[1] != "B" {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+}
+
+func TestCurdCompositeKeyWrongArity(t *testing.T) {
+	c := New[compositeKeyTable](&mockQuerier{}, nil, mockDialect{})
+
+	if _, err := c.FindByID(context.Background(), int64(1)); err == nil {
+		t.Error("expected error for missing key value")
+	}
+	if err := c.DeleteByID(context.Background(), 1, false); err == nil {
+		t.Error("expected error for missing key value")
+	}
+}
+
+func TestCurdCompositeKeyInsertKeepsKeyColumns(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[compositeKeyTable](mock, nil, mockDialect{})
+
+	if err := c.InsertOne(context.Background(), &compositeKeyTable{TenantID: 1, Code: "A"}); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	want := `INSERT INTO "composite_table" ("tenant_id","code","label") VALUES ($1,$2,$3)`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdCompositeKeySave(t *testing.T) {
+	mock := &upsertMock{existsVal: true}
+	c := New[compositeKeyTable](mock, nil, mockDialect{})
+
+	if err := c.Save(context.Background(), &compositeKeyTable{TenantID: 1, Code: "A", Label: "x"}); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	updates := structToUpdates(reflect.ValueOf(compositeKeyTable{Label: "x"}), defaultFieldMapper{}, nil)
+	if len(updates) != 1 || updates["label"] != "x" {
+		t.Errorf("expected only non-key columns in updates, got %v", updates)
+	}
+}
+
+func TestCurdDeleteByIDSoftStringKey(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[uuidKeyTable](mock, nil, mockDialect{})
+
+	if err := c.DeleteByID(context.Background(), "abc", false); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+	want := `UPDATE "uuid_table" SET "deleted_date" = $1 WHERE "uuid" = $2 AND "deleted_date" IS NULL`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+// ============================================
+// Soft Delete Policy Tests
+// ============================================
+
+type flagDeleteTable struct {
+	ID        int64 `json:"id"`
+	Name      string
+	IsDeleted bool  `json:"is_deleted"`
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+func (flagDeleteTable) TableName() string { return "flag_table" }
+
+func newFlagCurd(q Querier) *Curd[flagDeleteTable] {
+	return New[flagDeleteTable](q, nil, mockDialect{},
+		WithSoftDelete(SoftDeletePolicy{Field: "IsDeleted", Kind: SoftDeleteFlag}),
+		WithTimestamps(TimestampPolicy{CreatedField: "CreatedAt", ChangedField: "UpdatedAt"}),
+	)
+}
+
+func TestCurdDeleteWhereSoft(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 5}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if err := c.DeleteWhere(context.Background(), Eq("status", "expired")); err != nil {
+		t.Fatalf("DeleteWhere error: %v", err)
+	}
+	want := `UPDATE "test_table" SET "deleted_date" = $1 WHERE status = $2 AND "deleted_date" IS NULL`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if _, ok := mock.lastArgs[0].(time.Time); !ok {
+		t.Errorf("expected time.Time deletion value, got %T", mock.lastArgs[0])
+	}
+}
+
+func TestCurdDeleteWhereHardWithoutSoftDeleteField(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 2}}
+	c := New[*pointerTable](mock, nil, mockDialect{})
+
+	if err := c.DeleteWhere(context.Background(), Eq("name", "stale")); err != nil {
+		t.Fatalf("DeleteWhere error: %v", err)
+	}
+	if want := `DELETE FROM "pointer_table" WHERE name = $1`; mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdForceDelete(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if err := c.ForceDelete(context.Background(), Eq("id", 1)); err != nil {
+		t.Fatalf("ForceDelete error: %v", err)
+	}
+	if want := `DELETE FROM "test_table" WHERE id = $1`; mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdDeleteErrorsWrapOnce(t *testing.T) {
+	dbErr := errors.New("connection reset")
+	mock := &mockQuerier{execErr: dbErr}
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		run  func() error
+		want string
+	}{
+		{"DeleteByID hard", func() error { return New[testTable](mock, nil, mockDialect{}).DeleteByID(ctx, 1, true) }, "delete test_table: connection reset"},
+		{"DeleteByID soft", func() error { return New[testTable](mock, nil, mockDialect{}).DeleteByID(ctx, 1, false) }, "delete test_table: connection reset"},
+		{"DeleteWhere hard", func() error { return New[*pointerTable](mock, nil, mockDialect{}).DeleteWhere(ctx, Eq("id", 1)) }, "delete where pointer_table: connection reset"},
+		{"ForceDelete", func() error { return New[testTable](mock, nil, mockDialect{}).ForceDelete(ctx, Eq("id", 1)) }, "force delete test_table: connection reset"},
+	}
+	for _, tc := range cases {
+		err := tc.run()
+		if !errors.Is(err, dbErr) {
+			t.Errorf("%s: expected wrapped db error, got %v", tc.name, err)
+		} else if err.Error() != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, err.Error(), tc.want)
+		}
+	}
+}
+
+func TestCurdRestore(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if err := c.Restore(context.Background(), Eq("id", 1)); err != nil {
+		t.Fatalf("Restore error: %v", err)
+	}
+	want := `UPDATE "test_table" SET "deleted_date" = $1 WHERE id = $2 AND "deleted_date" IS NOT NULL`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if mock.lastArgs[0] != nil {
+		t.Errorf("expected NULL restore value, got %v", mock.lastArgs[0])
+	}
+}
+
+func TestCurdRestoreWithoutSoftDelete(t *testing.T) {
+	c := New[testTable](&mockQuerier{}, nil, mockDialect{}, WithSoftDelete(SoftDeletePolicy{}))
+	if err := c.Restore(context.Background(), Eq("id", 1)); err == nil {
+		t.Error("expected error when soft deletes are disabled")
+	}
+	if err := c.DeleteByID(context.Background(), 1, false); err == nil {
+		t.Error("expected error for soft DeleteByID when soft deletes are disabled")
+	}
+}
+
+func TestCurdFindTrashedScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  FindOption
+		want string
+	}{
+		{"live", WithWhere(Eq("age", 1)), ` WHERE age = $1 AND "deleted_date" IS NULL`},
+		{"with trashed", WithTrashed(), " WHERE age = $1"},
+		{"only trashed", OnlyTrashed(), ` WHERE age = $1 AND "deleted_date" IS NOT NULL`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockQuerier{queryRows: &mockRows{}}
+			c := New[testTable](mock, nil, mockDialect{})
+			if _, err := c.Find(context.Background(), WithWhere(Eq("age", 1)), tt.opt); err != nil {
+				t.Fatalf("Find error: %v", err)
+			}
+			if !strings.HasSuffix(mock.lastSQL, tt.want) {
+				t.Errorf("unexpected SQL: %s", mock.lastSQL)
+			}
+		})
+	}
+}
+
+func TestCurdFlagSoftDelete(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}, execResult: &mockResult{rowsAffected: 1}}
+	c := newFlagCurd(mock)
+
+	if _, err := c.Find(context.Background()); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, ` WHERE "is_deleted" = FALSE`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	if err := c.DeleteByID(context.Background(), 3, false); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+	if want := `UPDATE "flag_table" SET "is_deleted" = $1 WHERE "id" = $2 AND "is_deleted" = FALSE`; mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if mock.lastArgs[0] != true {
+		t.Errorf("expected true flag, got %v", mock.lastArgs[0])
+	}
+}
+
+type unixDeleteTable struct {
+	ID        int64 `json:"id"`
+	DeletedAt int64 `json:"deleted_at"`
+}
+
+func (unixDeleteTable) TableName() string { return "unix_table" }
+
+func TestCurdUnixSoftDelete(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[unixDeleteTable](mock, nil, mockDialect{},
+		WithSoftDelete(SoftDeletePolicy{Field: "DeletedAt", Kind: SoftDeleteUnix}))
+
+	if err := c.DeleteWhere(context.Background(), Eq("id", 1)); err != nil {
+		t.Fatalf("DeleteWhere error: %v", err)
+	}
+	want := `UPDATE "unix_table" SET "deleted_at" = $1 WHERE id = $2 AND "deleted_at" = 0`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if _, ok := mock.lastArgs[0].(int64); !ok {
+		t.Errorf("expected unix seconds, got %T", mock.lastArgs[0])
+	}
+}
+
+func TestCurdUnixTimestampsOnInsert(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{int64(1)}}}
+	c := newFlagCurd(mock)
+
+	row := &flagDeleteTable{Name: "n"}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if row.CreatedAt == 0 || row.UpdatedAt == 0 {
+		t.Errorf("expected unix timestamps to be set, got %+v", row)
+	}
+}
+
+func TestCurdUpdateWhereBumpsChangedDate(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTableWithTime](mock, nil, mockDialect{})
+
+	if err := c.UpdateWhere(context.Background(), Eq("id", 1), map[string]any{"created_date": time.Time{}}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	want := `UPDATE "test_time" SET "created_date" = $1,"changed_date" = $2 WHERE id = $3`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+
+	// An explicit value is kept.
+	explicit := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := c.UpdateWhere(context.Background(), Eq("id", 1), map[string]any{"changed_date": explicit}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	if len(mock.lastArgs) != 2 || mock.lastArgs[0] != explicit {
+		t.Errorf("expected explicit changed_date to be kept, got %v", mock.lastArgs)
+	}
+}
+
+func TestSetNowUnixAndPointer(t *testing.T) {
+	type s struct {
+		At  int64
+		Ptr *time.Time
+	}
+	row := &s{}
+	setNow(reflect.ValueOf(row), "At")
+	setNow(reflect.ValueOf(row), "Ptr")
+	if row.At == 0 {
+		t.Error("setNow should set unix seconds on integer fields")
+	}
+	if row.Ptr == nil || row.Ptr.IsZero() {
+		t.Error("setNow should set *time.Time fields")
+	}
+}
+
+// ============================================
+// Optimistic Locking Tests
+// ============================================
+
+type versionedTable struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Version int64  `json:"version" curd:"version"`
+}
+
+func (versionedTable) TableName() string { return "versioned" }
+
+func TestVersionFieldOf(t *testing.T) {
+	vf := versionFieldOf(reflect.TypeOf(versionedTable{}), defaultFieldMapper{})
+	if vf == nil || vf.column != "version" {
+		t.Fatalf("expected version column, got %+v", vf)
+	}
+	if versionFieldOf(reflect.TypeOf(testTable{}), defaultFieldMapper{}) != nil {
+		t.Error("untagged entity should not be versioned")
+	}
+}
+
+func TestCurdUpdateWhereVersionChecked(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	err := c.UpdateByID(context.Background(), 7, map[string]any{"name": "n", "version": int64(3)})
+	if err != nil {
+		t.Fatalf("UpdateByID error: %v", err)
+	}
+	want := `UPDATE "versioned" SET "name" = $1,"version" = "version" + 1 WHERE "id" = $2 AND "version" = $3`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if len(mock.lastArgs) != 3 || mock.lastArgs[2] != int64(3) {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+}
+
+func TestCurdUpdateWhereVersionUnknownCount(t *testing.T) {
+	// A driver that cannot count rows reports -1, which is not a stale row.
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: -1}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	if err := c.UpdateByID(context.Background(), 7, map[string]any{"name": "n", "version": int64(3)}); err != nil {
+		t.Fatalf("UpdateByID error: %v", err)
+	}
+}
+
+func TestCurdUpdateWhereVersionUnchecked(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 0}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	if err := c.UpdateWhere(context.Background(), Eq("name", "a"), map[string]any{"name": "b"}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	want := `UPDATE "versioned" SET "name" = $1,"version" = "version" + 1 WHERE name = $2`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+}
+
+func TestCurdUpdateWhereStaleObject(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 0}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	err := c.UpdateByID(context.Background(), 7, map[string]any{"name": "n", "version": int64(3)})
+	if !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+}
+
+func TestCurdSaveVersioned(t *testing.T) {
+	mock := &upsertMock{existsVal: true}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	row := &versionedTable{ID: 1, Name: "x", Version: 4}
+	if err := c.Save(context.Background(), row); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if row.Version != 5 {
+		t.Errorf("expected version to advance to 5, got %d", row.Version)
+	}
+}
+
+func TestCurdSaveVersionedStale(t *testing.T) {
+	mock := &staleMock{}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	row := &versionedTable{ID: 1, Name: "x", Version: 4}
+	err := c.Save(context.Background(), row)
+	if !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+	if row.Version != 4 {
+		t.Errorf("stale update must not advance the version, got %d", row.Version)
+	}
+}
+
+func TestCurdUpdateBatchVersioned(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	rows := []*versionedTable{{ID: 1, Name: "a", Version: 1}, {ID: 2, Name: "b", Version: 9}}
+	if err := c.UpdateBatch(context.Background(), rows); err != nil {
+		t.Fatalf("UpdateBatch error: %v", err)
+	}
+	if mock.execCount != 2 {
+		t.Errorf("expected 2 statements, got %d", mock.execCount)
+	}
+	if rows[0].Version != 2 || rows[1].Version != 10 {
+		t.Errorf("expected versions to advance, got %d and %d", rows[0].Version, rows[1].Version)
+	}
+	if !strings.HasSuffix(mock.lastSQL, `WHERE "id" = $2 AND "version" = $3`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+func TestCurdUpdateBatchStale(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 0}}
+	c := New[versionedTable](mock, nil, mockDialect{})
+
+	err := c.UpdateBatch(context.Background(), []*versionedTable{{ID: 1, Version: 1}, {ID: 2, Version: 1}})
+	if !errors.Is(err, ErrStaleObject) {
+		t.Fatalf("expected ErrStaleObject, got %v", err)
+	}
+	if mock.execCount != 1 {
+		t.Errorf("batch should stop at the first stale row, ran %d statements", mock.execCount)
+	}
+}
+
+// staleMock reports an existing row but matches no row on update.
+type staleMock struct{ upsertMock }
+
+func (m *staleMock) QueryRow(ctx context.Context, sql string, args ...any) Row {
+	return &mockRow{record: []any{true}}
+}
+
+func (m *staleMock) Exec(ctx context.Context, sql string, args ...any) (Result, error) {
+	return &mockResult{rowsAffected: 0}, nil
+}
+
+// ============================================
+// Lifecycle Hook Tests
+// ============================================
+
+type hookedTable struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Loaded   bool   `json:"-"`
+	Inserted bool   `json:"-"`
+}
+
+func (hookedTable) TableName() string { return "hooked" }
+
+var errHookVeto = errors.New("veto")
+
+func (h *hookedTable) BeforeInsert(ctx context.Context) error {
+	if h.Name == "" {
+		return errHookVeto
+	}
+	h.Slug = strings.ToLower(h.Name)
+	return nil
+}
+
+func (h *hookedTable) AfterInsert(ctx context.Context) error {
+	h.Inserted = true
+	return nil
+}
+
+func (h *hookedTable) BeforeUpdate(ctx context.Context) error {
+	if h.Name == "" {
+		return errHookVeto
+	}
+	h.Slug = strings.ToLower(h.Name)
+	return nil
+}
+
+func (h *hookedTable) AfterFind(ctx context.Context) error {
+	h.Loaded = true
+	return nil
+}
+
+func (h *hookedTable) BeforeScopedUpdate(ctx context.Context, scope WriteScope, updates map[string]any) error {
+	if name, ok := updates["name"]; ok && name == "" {
+		return errHookVeto
+	}
+	return nil
+}
+
+func (h *hookedTable) BeforeScopedDelete(ctx context.Context, scope WriteScope) error {
+	if len(scope.Key) == 1 && scope.Key[0] == 1 {
+		return errHookVeto
+	}
+	return nil
+}
+
+func TestHookInsertOne(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{record: []any{int64(5)}}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	row := &hookedTable{Name: "Hello"}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if row.Slug != "hello" || !row.Inserted || row.ID != 5 {
+		t.Errorf("hooks not applied: %+v", row)
+	}
+	if mock.lastArgs[1] != "hello" {
+		t.Errorf("BeforeInsert changes should be inserted, got args %v", mock.lastArgs)
+	}
+}
+
+func TestHookInsertAborts(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	if err := c.InsertOne(context.Background(), &hookedTable{}); !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	err := c.InsertBatch(context.Background(), []hookedTable{{Name: "a"}, {}})
+	if !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected hook error from batch, got %v", err)
+	}
+	if mock.lastSQL != "" {
+		t.Errorf("no statement should run when a hook fails, got %s", mock.lastSQL)
+	}
+}
+
+func TestHookInsertBatch(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 2}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	rows := []hookedTable{{Name: "A"}, {Name: "B"}}
+	if err := c.InsertBatch(context.Background(), rows); err != nil {
+		t.Fatalf("InsertBatch error: %v", err)
+	}
+	if rows[0].Slug != "a" || rows[1].Slug != "b" || !rows[1].Inserted {
+		t.Errorf("hooks not applied: %+v", rows)
+	}
+}
+
+func TestHookUpsertBatchOnConflict(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 2}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	rows := []hookedTable{{Name: "A"}, {Name: "B"}}
+	if err := c.UpsertBatchOnConflict(context.Background(), rows, []string{"name"}, nil); err != nil {
+		t.Fatalf("UpsertBatchOnConflict error: %v", err)
+	}
+	for _, r := range rows {
+		if r.Slug != strings.ToLower(r.Name) || !r.Inserted {
+			t.Errorf("insert hooks should run on every row: %+v", r)
+		}
+	}
+	err := c.UpsertBatchOnConflict(context.Background(), []hookedTable{{Name: "a"}, {}}, []string{"name"}, nil)
+	if !errors.Is(err, errHookVeto) {
+		t.Errorf("expected hook error from batch upsert, got %v", err)
+	}
+}
+
+func TestHookInsertIgnoreBatchSkipsAfterInsert(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	rows := []hookedTable{{Name: "A"}, {Name: "B"}}
+	if _, err := c.InsertIgnoreBatch(context.Background(), rows, []string{"name"}); err != nil {
+		t.Fatalf("InsertIgnoreBatch error: %v", err)
+	}
+	if rows[0].Slug != "a" || rows[1].Slug != "b" {
+		t.Errorf("BeforeInsert should run on every row: %+v", rows)
+	}
+	if rows[0].Inserted || rows[1].Inserted {
+		t.Errorf("AfterInsert should not run when skipped rows are unknown: %+v", rows)
+	}
+}
+
+func TestHookAfterFind(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(1), "a", "a"}}}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	rows, err := c.Find(context.Background())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if len(rows) != 1 || !rows[0].Loaded {
+		t.Errorf("AfterFind not called: %+v", rows)
+	}
+
+	mock.queryRows = &mockRows{records: [][]any{{int64(1), "a", "a"}}}
+	for row, err := range c.Iter(context.Background()) {
+		if err != nil {
+			t.Fatalf("Iter error: %v", err)
+		}
+		if !row.Loaded {
+			t.Error("AfterFind not called on Iter")
+		}
+	}
+}
+
+func TestHookAfterFindPointerEntity(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(1), "a", "a"}}}}
+	rows, err := QueryRaw[*hookedTable](context.Background(), mock, "SELECT id, name, slug FROM hooked")
+	if err != nil {
+		t.Fatalf("QueryRaw error: %v", err)
+	}
+	if len(rows) != 1 || !rows[0].Loaded {
+		t.Errorf("AfterFind not called: %+v", rows)
+	}
+}
+
+func TestHookBeforeUpdateOnSave(t *testing.T) {
+	mock := &upsertMock{existsVal: true}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	row := &hookedTable{ID: 3, Name: "Up"}
+	if err := c.Save(context.Background(), row); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	if row.Slug != "up" {
+		t.Errorf("BeforeUpdate not called: %+v", row)
+	}
+}
+
+func TestHookPredicateWritesSkipRowHooks(t *testing.T) {
+	// BeforeUpdate rejects rows without a name; predicate-based writes have
+	// no row, so they must not run it on a zero T.
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+	ctx := context.Background()
+
+	if err := c.UpdateByID(ctx, 2, map[string]any{"slug": "x"}); err != nil {
+		t.Fatalf("UpdateByID error: %v", err)
+	}
+	if err := c.UpdateWhere(ctx, Eq("slug", "x"), map[string]any{"slug": "y"}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	if err := c.DeleteWhere(ctx, Eq("slug", "y")); err != nil {
+		t.Fatalf("DeleteWhere error: %v", err)
+	}
+	if mock.execCount != 3 {
+		t.Errorf("expected 3 statements, got %d", mock.execCount)
+	}
+}
+
+func TestHookBeforeScopedUpdate(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	if err := c.UpdateByID(context.Background(), 2, map[string]any{"name": ""}); !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected BeforeScopedUpdate veto, got %v", err)
+	}
+	if err := c.UpdateWhere(context.Background(), Eq("id", 2), map[string]any{"name": ""}); !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected BeforeScopedUpdate veto, got %v", err)
+	}
+	if mock.execCount != 0 {
+		t.Error("vetoed update must not run")
+	}
+}
+
+func TestHookBeforeScopedDelete(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[hookedTable](mock, nil, mockDialect{})
+
+	if err := c.DeleteByID(context.Background(), 1, true); !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected BeforeScopedDelete veto, got %v", err)
+	}
+	if mock.execCount != 0 {
+		t.Error("vetoed delete must not run")
+	}
+	if err := c.DeleteByID(context.Background(), 2, true); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+}
+
+// lockedTable refuses to delete rows marked locked.
+type lockedTable struct {
+	ID     int64 `json:"id"`
+	Locked bool  `json:"locked"`
+}
+
+func (lockedTable) TableName() string { return "locked_rows" }
+
+func (l *lockedTable) BeforeDelete(ctx context.Context) error {
+	if l.Locked {
+		return errHookVeto
+	}
+	return nil
+}
+
+func TestHookBeforeDeleteSeesLoadedRow(t *testing.T) {
+	mock := &mockQuerier{
+		queryRows:  &mockRows{records: [][]any{{int64(1), true}}},
+		execResult: &mockResult{rowsAffected: 1},
+	}
+	c := New[lockedTable](mock, nil, mockDialect{})
+
+	if err := c.DeleteByID(context.Background(), 1, true); !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected BeforeDelete veto on the loaded row, got %v", err)
+	}
+	if mock.execCount != 0 {
+		t.Error("vetoed delete must not run")
+	}
+
+	mock.queryRows = &mockRows{records: [][]any{{int64(2), false}}}
+	if err := c.DeleteByID(context.Background(), 2, true); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+	if mock.lastSQL != `DELETE FROM "locked_rows" WHERE "id" = $1` {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	// No matching row: nothing to run the hook on, the delete goes ahead.
+	mock.queryRows = &mockRows{}
+	if err := c.DeleteByID(context.Background(), 3, true); err != nil {
+		t.Fatalf("DeleteByID error: %v", err)
+	}
+}
+
+func TestHookErrorRollsBackTx(t *testing.T) {
+	tx := &mockTx{Querier: &mockQuerier{execResult: &mockResult{rowsAffected: 1}}}
+	b := &mockTxBeginner{tx: tx}
+	c := New[hookedTable](nil, nil, mockDialect{})
+
+	err := WithTx(context.Background(), b, func(ctx context.Context, q Querier) error {
+		return c.WithQuerier(q).InsertOne(ctx, &hookedTable{})
+	})
+	if !errors.Is(err, errHookVeto) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	if !tx.rolledBack || tx.committed {
+		t.Error("hook error should roll back the transaction")
+	}
+}
+
+// ============================================
+// Field Decoder Tests
+// ============================================
+
+type decodedTable struct {
+	ID       int64          `json:"id"`
+	Metadata map[string]any `json:"metadata"`
+	Config   *decodedConfig `json:"config"`
+	Document decodedDoc     `json:"document"`
+}
+
+type decodedConfig struct {
+	Env  string `json:"env"`
+	Port int    `json:"port"`
+}
+
+type decodedDoc struct {
+	XMLName struct{} `xml:"doc"`
+	Title   string   `xml:"title"`
+}
+
+func (decodedTable) TableName() string { return "decoded" }
+
+func TestJSONBUnmarshaler(t *testing.T) {
+	d := JSONBUnmarshaler("config")
+
+	got, err := d("config", `{"env":"prod","port":8080}`, reflect.TypeOf(&decodedConfig{}))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	cfg, ok := got.(*decodedConfig)
+	if !ok || cfg.Env != "prod" || cfg.Port != 8080 {
+		t.Errorf("unexpected decoded value: %#v", got)
+	}
+
+	// Values already decoded by the driver are re-encoded.
+	got, err = d("config", map[string]any{"env": "dev"}, reflect.TypeOf(decodedConfig{}))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if got.(decodedConfig).Env != "dev" {
+		t.Errorf("unexpected decoded value: %#v", got)
+	}
+
+	// Other fields pass through.
+	if got, _ := d("other", "x", reflect.TypeOf("")); got != "x" {
+		t.Errorf("expected passthrough, got %v", got)
+	}
+
+	if _, err := d("config", "{broken", reflect.TypeOf(decodedConfig{})); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestXMLUnmarshaler(t *testing.T) {
+	d := XMLUnmarshaler("document")
+	got, err := d("document", []byte("<doc><title>hi</title></doc>"), reflect.TypeOf(decodedDoc{}))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if got.(decodedDoc).Title != "hi" {
+		t.Errorf("unexpected decoded value: %#v", got)
+	}
+}
+
+func TestComposeDecoders(t *testing.T) {
+	upper := func(name string, v any, _ reflect.Type) (any, error) {
+		if s, ok := v.(string); ok {
+			return strings.ToUpper(s), nil
+		}
+		return v, nil
+	}
+	d := ComposeDecoders(upper, func(name string, v any, _ reflect.Type) (any, error) {
+		return v.(string) + "!", nil
+	})
+	got, err := d("x", "a", reflect.TypeOf(""))
+	if err != nil || got != "A!" {
+		t.Errorf("expected A!, got %v (%v)", got, err)
+	}
+}
+
+func TestCurdFindWithDecoder(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{
+		int64(1),
+		[]byte(`{"a":1}`),
+		`{"env":"prod","port":1}`,
+		"<doc><title>t</title></doc>",
+	}}}}
+	c := New[decodedTable](mock, nil, mockDialect{}).
+		WithDecoder(JSONBUnmarshaler("metadata", "config")).
+		WithDecoder(XMLUnmarshaler("document"))
+
+	rows, err := c.Find(context.Background())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(rows))
+	}
+	r := rows[0]
+	if r.Metadata["a"] != float64(1) || r.Config == nil || r.Config.Env != "prod" || r.Document.Title != "t" {
+		t.Errorf("fields not decoded: %+v", r)
+	}
+}
+
+func TestCurdFindDecoderError(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(1), "{bad", nil, nil}}}}
+	c := New[decodedTable](mock, nil, mockDialect{}).WithDecoder(JSONBUnmarshaler("metadata"))
+
+	if _, err := c.FindAll(context.Background(), nil, "", 0, 0); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestQueryRawWithContextDecoder(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{`{"env":"raw"}`}}}}
+	ctx := ContextWithDecoder(context.Background(), JSONBUnmarshaler("Config"))
+
+	rows, err := QueryRaw[struct{ Config decodedConfig }](ctx, mock, "SELECT config FROM decoded")
+	if err != nil {
+		t.Fatalf("QueryRaw error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Config.Env != "raw" {
+		t.Errorf("expected decoded config, got %+v", rows)
+	}
+}
+
+// ============================================
+// Error Taxonomy Tests
+// ============================================
+
+var errDriverDup = errors.New("driver: duplicate key")
+
+// translatingDialect maps errDriverDup onto ErrUniqueViolation.
+type translatingDialect struct{ mockDialect }
+
+func (translatingDialect) TranslateError(err error) error {
+	if errors.Is(err, errDriverDup) {
+		return &DBError{Kind: ErrUniqueViolation, Constraint: "t_name_key", Column: "name", Err: err}
+	}
+	return err
+}
+
+// deadlockQuerier maps every error onto ErrDeadlock.
+type deadlockQuerier struct{ mockQuerier }
+
+func (*deadlockQuerier) TranslateError(err error) error {
+	return &DBError{Kind: ErrDeadlock, Err: err}
+}
+
+func TestCurdFindOneErrNotFound(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	_, err := c.FindOne(context.Background(), Eq("id", 1))
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := c.FindByID(context.Background(), 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from FindByID, got %v", err)
+	}
+}
+
+func TestCurdDialectTranslatesErrors(t *testing.T) {
+	mock := &mockQuerier{queryRow: &mockRow{err: errDriverDup}, execErr: errDriverDup}
+	c := New[testTable](mock, nil, translatingDialect{})
+
+	err := c.InsertOne(context.Background(), &testTable{Name: "dup"})
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("expected ErrUniqueViolation, got %v", err)
+	}
+	var dbErr *DBError
+	if !errors.As(err, &dbErr) || dbErr.Constraint != "t_name_key" || dbErr.Column != "name" {
+		t.Errorf("expected constraint details, got %+v", dbErr)
+	}
+	if !errors.Is(err, errDriverDup) {
+		t.Error("translated error should unwrap to the driver error")
+	}
+
+	err = c.UpdateWhere(context.Background(), Eq("id", 1), map[string]any{"name": "dup"})
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("expected ErrUniqueViolation from update, got %v", err)
+	}
+}
+
+func TestCurdQuerierTranslatesErrors(t *testing.T) {
+	q := &deadlockQuerier{mockQuerier{queryErr: errors.New("boom")}}
+	c := New[testTable](q, nil, mockDialect{})
+
+	if _, err := c.Find(context.Background()); !errors.Is(err, ErrDeadlock) {
+		t.Fatalf("expected ErrDeadlock from Find, got %v", err)
+	}
+	if _, err := QueryRaw[testTable](context.Background(), q, "SELECT 1"); !errors.Is(err, ErrDeadlock) {
+		t.Fatalf("expected ErrDeadlock from QueryRaw, got %v", err)
+	}
+}
+
+func TestCurdNoTranslatorPassesThrough(t *testing.T) {
+	boom := errors.New("boom")
+	c := New[testTable](&mockQuerier{execErr: boom}, nil, mockDialect{})
+	err := c.DeleteByID(context.Background(), 1, true)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected driver error, got %v", err)
+	}
+}
+
+func TestDBErrorMessage(t *testing.T) {
+	err := &DBError{Kind: ErrUniqueViolation, Constraint: "users_email_key", Column: "email", Err: errors.New("dup")}
+	want := "curd: unique violation (constraint users_email_key, column email): dup"
+	if err.Error() != want {
+		t.Errorf("unexpected message:\n got: %s\nwant: %s", err.Error(), want)
+	}
+	if errors.Is(err, ErrForeignKeyViolation) {
+		t.Error("DBError should only match its own kind")
+	}
+}
+
+// ============================================
+// Column Whitelist Tests
+// ============================================
+
+func TestColumnSetResolve(t *testing.T) {
+	set := columnSetOf(reflect.TypeFor[testTableGorm](), defaultFieldMapper{})
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"title_name", "title_name", true},
+		{"Title", "title_name", true},
+		{"t.status_code", "t.status_code", true},
+		{"t.Status", "t.status_code", true},
+		{"Content", "", false},
+		{"title", "", false},
+		{"title_name; DROP TABLE x", "", false},
+		{"lower(title_name)", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := set.resolve(tt.in)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("resolve(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+		if !tt.ok && !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("resolve(%q): expected ErrUnknownColumn, got %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestCurdUpdateWhereRejectsUnknownColumn(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	err := c.UpdateByID(context.Background(), 1, map[string]any{"name = 'x', age": 1})
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn, got %v", err)
+	}
+	if mock.execCount != 0 {
+		t.Error("no statement should run")
+	}
+}
+
+func TestCurdUpdateWhereResolvesFieldNames(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if err := c.UpdateWhere(context.Background(), Eq("id", 1), map[string]any{"Name": "n"}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	if mock.lastSQL != `UPDATE "test_table" SET "name" = $1 WHERE id = $2` {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	err := c.UpdateWhere(context.Background(), Eq("id", 1), map[string]any{"Name": "a", "name": "b"})
+	if err == nil {
+		t.Error("expected error for a column set twice")
+	}
+}
+
+func TestCurdFindOrderWhitelist(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockPositionalDialect{})
+
+	if _, err := c.Find(context.Background(), WithOrder(Desc("Age").NullsLast(), Asc("t.id"))); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, " ORDER BY `age` DESC NULLS LAST, `t`.`id` ASC") {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	if _, err := c.Find(context.Background(), WithOrderBy("name desc nulls first, id")); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.HasSuffix(mock.lastSQL, " ORDER BY `name` DESC NULLS FIRST, `id` ASC") {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	for _, orderBy := range []string{"password", "id; DROP TABLE test_table", "(SELECT 1) DESC", "id ASC,", "id SIDEWAYS"} {
+		mock.lastSQL = ""
+		if _, err := c.Find(context.Background(), WithOrderBy(orderBy)); err == nil {
+			t.Errorf("WithOrderBy(%q): expected error", orderBy)
+		}
+		if mock.lastSQL != "" {
+			t.Errorf("WithOrderBy(%q): no query should run, got %s", orderBy, mock.lastSQL)
+		}
+	}
+	if _, err := c.FindAll(context.Background(), nil, "1 DESC", 0, 0); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("FindAll: expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := c.FindAfter(context.Background(), "", WithOrder(Asc("nope"))); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("FindAfter: expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestCurdFindColumnsWhitelist(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if _, err := c.Find(context.Background(), WithColumns("ID", "t.name")); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.HasPrefix(mock.lastSQL, `SELECT "id","t"."name" FROM`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+
+	_, err := c.Find(context.Background(), WithColumns("id", "(SELECT secret FROM users)"))
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+	for _, err := range c.Iter(context.Background(), WithColumns("nope")) {
+		if !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("Iter: expected ErrUnknownColumn, got %v", err)
+		}
+	}
+}
+
+func TestCurdFindJoinedColumns(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	_, err := c.Find(context.Background(),
+		WithJoins(JoinClause{Type: LeftJoin, Table: "roles AS r", On: "r.name = test_table.name"}),
+		WithColumns("test_table.id", "test_table.name", "r.label"),
+		WithOrderBy("r.label ASC, test_table.id ASC"),
+	)
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	want := `SELECT "test_table"."id","test_table"."name","r"."label" FROM "test_table" LEFT JOIN roles AS r ON r.name = test_table.name` +
+		` WHERE "test_table"."deleted_date" IS NULL ORDER BY "r"."label" ASC, "test_table"."id" ASC`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+
+	// Only joined tables qualify unknown columns, and only plain names.
+	join := WithJoins(JoinClause{Type: InnerJoin, Table: "roles r", On: "r.name = test_table.name"})
+	for _, col := range []string{"x.label", "label", "r.(label)", "roles.label; DROP"} {
+		if _, err := c.Find(context.Background(), join, WithColumns(col)); !errors.Is(err, ErrUnknownColumn) {
+			t.Errorf("WithColumns(%q): expected ErrUnknownColumn, got %v", col, err)
+		}
+	}
+	if _, err := c.Find(context.Background(), join, WithColumns("roles.label", "r.label")); err != nil {
+		t.Errorf("expected the table name and alias to qualify, got %v", err)
+	}
+	if _, err := c.Find(context.Background(), WithColumns("r.label")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("without the join: expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestCurdPluckRejectsUnknownColumn(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[testTable](mock, nil, mockDialect{})
+
+	if _, err := c.Pluck(context.Background(), "Name", nil); err != nil {
+		t.Fatalf("Pluck error: %v", err)
+	}
+	if !strings.HasPrefix(mock.lastSQL, `SELECT "name" FROM "test_table"`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+	if _, err := c.Pluck(context.Background(), "count(*)", nil); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+// ============================================
+// Aggregate Tests
+// ============================================
+
+type orderRow struct {
+	ID          int64   `json:"id"`
+	Status      string  `json:"status"`
+	Amount      float64 `json:"amount"`
+	CustomerID  int64   `json:"customer_id"`
+	DeletedDate *string `json:"deleted_date"`
+}
+
+func (orderRow) TableName() string { return "orders" }
+
+type statusTotal struct {
+	Status string  `json:"status"`
+	Total  float64 `json:"total"`
+	Orders int64   `json:"orders"`
+}
+
+func TestAggregate(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{
+		{"paid", float64(250), int64(3)},
+		{"open", int64(40), int64(1)},
+	}}}
+	c := New[orderRow](mock, nil, mockDialect{})
+
+	got, err := Aggregate[statusTotal](context.Background(), c,
+		WithWhere(Gt("customer_id", 0)),
+		WithGroupBy("status"),
+		WithAggregates(Count("*").As("orders"), Sum("amount").As("total")),
+		WithHaving(Gt(Sum("amount").Expr(), 10)),
+		WithOrder(Desc("total"), Asc("status")),
+		WithLimit(5),
+	)
+	if err != nil {
+		t.Fatalf("Aggregate error: %v", err)
+	}
+	want := `SELECT "status", SUM("amount") AS "total", COUNT(*) AS "orders" FROM "orders"` +
+		` WHERE customer_id > $1 AND "deleted_date" IS NULL GROUP BY "status" HAVING SUM(amount) > $2` +
+		` ORDER BY "total" DESC, "status" ASC LIMIT $3`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if !reflect.DeepEqual(mock.lastArgs, []any{0, 10, 5}) {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+	wantRows := []statusTotal{{"paid", 250, 3}, {"open", 40, 1}}
+	if !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("got %+v, want %+v", got, wantRows)
+	}
+}
+
+func TestAggregateWithoutGroupBy(t *testing.T) {
+	type summary struct {
+		MaxAmount float64 `json:"max_amount"`
+		Customers int64   `json:"customers"`
+	}
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{float64(99), int64(4)}}}}
+	c := New[orderRow](mock, nil, mockPositionalDialect{})
+
+	got, err := Aggregate[summary](context.Background(), c,
+		WithAggregates(Max("amount"), Count("customer_id").Distinct().As("customers")),
+		WithTrashed(),
+	)
+	if err != nil {
+		t.Fatalf("Aggregate error: %v", err)
+	}
+	want := "SELECT MAX(`amount`) AS `max_amount`, COUNT(DISTINCT `customer_id`) AS `customers` FROM `orders`"
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if len(got) != 1 || got[0].MaxAmount != 99 || got[0].Customers != 4 {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	c := New[orderRow](&mockQuerier{queryRows: &mockRows{}}, nil, mockDialect{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts []FindOption
+		is   error
+	}{
+		{"ungrouped result column", []FindOption{WithAggregates(Sum("amount").As("total"))}, nil},
+		{"unknown group column", []FindOption{WithGroupBy("region"), WithAggregates(Sum("amount").As("total"), Count("*").As("orders"))}, ErrUnknownColumn},
+		{"unknown aggregate column", []FindOption{WithGroupBy("status"), WithAggregates(Sum("price").As("total"), Count("*").As("orders"))}, ErrUnknownColumn},
+		{"unknown order column", []FindOption{WithGroupBy("status"), WithAggregates(Sum("amount").As("total"), Count("*").As("orders")), WithOrderBy("secret")}, ErrUnknownColumn},
+		{"invalid alias", []FindOption{WithGroupBy("status"), WithAggregates(Sum("amount").As("total; --"), Count("*").As("orders"))}, nil},
+	}
+	for _, tt := range tests {
+		_, err := Aggregate[statusTotal](ctx, c, tt.opts...)
+		if err == nil {
+			t.Errorf("%s: expected error", tt.name)
+			continue
+		}
+		if tt.is != nil && !errors.Is(err, tt.is) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.is, err)
+		}
+	}
+}
+
+// ============================================
+// Subquery Tests
+// ============================================
+
+func TestSubqueryInQuery(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	users := New[testTable](mock, nil, mockDialect{})
+	orders := New[orderRow](mock, nil, mockDialect{})
+
+	adults, err := users.Subquery(WithColumns("id"), WithWhere(Gte("age", 18)), WithLimit(100))
+	if err != nil {
+		t.Fatalf("Subquery error: %v", err)
+	}
+	_, err = orders.Find(context.Background(),
+		WithWhere(And(Eq("status", "open"), InQuery("customer_id", adults), Gt("amount", 10))),
+		WithLimit(5),
+	)
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	want := `SELECT "id","status","amount","customer_id","deleted_date" FROM "orders" WHERE (status = $1 AND` +
+		` customer_id IN (SELECT "id" FROM "test_table" WHERE age >= $2 AND "deleted_date" IS NULL LIMIT $3) AND amount > $4)` +
+		` AND "deleted_date" IS NULL LIMIT $5`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if !reflect.DeepEqual(mock.lastArgs, []any{"open", 18, 100, 10, 5}) {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+}
+
+func TestSubqueryExistsInUpdate(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}}
+	users := New[testTable](mock, nil, mockDialect{})
+	orders := New[orderRow](mock, nil, mockDialect{})
+
+	paid, err := orders.Subquery(WithColumns("id"), WithWhere(Eq("status", "paid")), WithTrashed())
+	if err != nil {
+		t.Fatalf("Subquery error: %v", err)
+	}
+	if err := users.UpdateWhere(context.Background(), ExistsQuery(paid), map[string]any{"name": "buyer"}); err != nil {
+		t.Fatalf("UpdateWhere error: %v", err)
+	}
+	want := `UPDATE "test_table" SET "name" = $1 WHERE EXISTS (SELECT "id" FROM "orders" WHERE status = $2)`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if !reflect.DeepEqual(mock.lastArgs, []any{"buyer", "paid"}) {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+}
+
+func TestSubqueryPositionalDialect(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	users := New[testTable](mock, nil, mockPositionalDialect{})
+	orders := New[orderRow](mock, nil, mockPositionalDialect{})
+
+	sub, err := orders.Subquery(WithColumns("customer_id"), WithWhere(Gt("amount", 100)))
+	if err != nil {
+		t.Fatalf("Subquery error: %v", err)
+	}
+	if _, err := users.Find(context.Background(), WithWhere(And(Eq("name", "a"), InQuery("id", sub)))); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.Contains(mock.lastSQL, "WHERE (name = ? AND id IN (SELECT `customer_id` FROM `orders` WHERE amount > ? AND `deleted_date` IS NULL))") {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+	if !reflect.DeepEqual(mock.lastArgs, []any{"a", 100}) {
+		t.Errorf("unexpected args: %v", mock.lastArgs)
+	}
+}
+
+func TestSubqueryRejectsUnknownColumn(t *testing.T) {
+	c := New[orderRow](&mockQuerier{}, nil, mockDialect{})
+	if _, err := c.Subquery(WithColumns("password")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expected ErrUnknownColumn, got %v", err)
+	}
+	clause, _, _ := buildPredicate(InQuery("id", Subquery{}), mockDialect{})
+	if clause != "FALSE" {
+		t.Errorf("zero Subquery should render FALSE, got %q", clause)
+	}
+}
+
+// ============================================
+// Preload Tests
+// ============================================
+
+type preloadCustomer struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	DeletedDate *string `json:"deleted_date"`
+}
+
+func (preloadCustomer) TableName() string { return "customers" }
+
+type preloadProduct struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (preloadProduct) TableName() string { return "products" }
+
+type preloadItem struct {
+	ID        int64           `json:"id"`
+	OrderID   int64           `json:"order_id"`
+	ProductID int64           `json:"product_id"`
+	Product   *preloadProduct `json:"product" curd:"belongs_to"`
+}
+
+func (preloadItem) TableName() string { return "order_items" }
+
+type preloadOrder struct {
+	ID         int64            `json:"id"`
+	CustomerID int64            `json:"customer_id"`
+	Customer   *preloadCustomer `json:"customer" curd:"belongs_to"`
+	Items      []preloadItem    `json:"items" curd:"has_many;fk:order_id"`
+}
+
+func (preloadOrder) TableName() string { return "orders" }
+
+func TestPreload(t *testing.T) {
+	kq := &keysetQuerier{eachQuerier: eachQuerier{batches: []*mockRows{
+		{records: [][]any{{int64(1), int64(10)}, {int64(2), int64(10)}, {int64(3), int64(11)}}},
+		{records: [][]any{{int64(10), "ann", nil}}},
+		{records: [][]any{{int64(100), int64(1), int64(7)}, {int64(101), int64(1), int64(8)}, {int64(102), int64(2), int64(7)}}},
+		{records: [][]any{{int64(7), "pen"}, {int64(8), "ink"}}},
+	}}}
+	c := New[preloadOrder](kq, nil, mockDialect{})
+
+	orders, err := c.Find(context.Background(), WithPreload("Customer", "Items.Product"))
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	wantSQL := []string{
+		`SELECT "id","customer_id" FROM "orders"`,
+		`SELECT "id","name","deleted_date" FROM "customers" WHERE "id" IN ($1, $2) AND "deleted_date" IS NULL`,
+		`SELECT "id","order_id","product_id" FROM "order_items" WHERE "order_id" IN ($1, $2, $3)`,
+		`SELECT "id","name" FROM "products" WHERE "id" IN ($1, $2)`,
+	}
+	if !reflect.DeepEqual(kq.sqls, wantSQL) {
+		t.Fatalf("unexpected queries:\n got: %q\nwant: %q", kq.sqls, wantSQL)
+	}
+	if !reflect.DeepEqual(kq.args[1], []any{int64(10), int64(11)}) {
+		t.Errorf("unexpected customer args: %v", kq.args[1])
+	}
+	if !reflect.DeepEqual(kq.args[3], []any{int64(7), int64(8)}) {
+		t.Errorf("unexpected product args: %v", kq.args[3])
+	}
+
+	if len(orders) != 3 {
+		t.Fatalf("expected 3 orders, got %d", len(orders))
+	}
+	if orders[0].Customer == nil || orders[0].Customer.Name != "ann" || orders[1].Customer != orders[0].Customer {
+		t.Errorf("orders 1 and 2 should share customer ann, got %+v and %+v", orders[0].Customer, orders[1].Customer)
+	}
+	if orders[2].Customer != nil {
+		t.Errorf("order 3 customer is deleted, got %+v", orders[2].Customer)
+	}
+	if len(orders[0].Items) != 2 || orders[0].Items[1].Product == nil || orders[0].Items[1].Product.Name != "ink" {
+		t.Errorf("unexpected items of order 1: %+v", orders[0].Items)
+	}
+	if len(orders[1].Items) != 1 || orders[1].Items[0].Product.Name != "pen" {
+		t.Errorf("unexpected items of order 2: %+v", orders[1].Items)
+	}
+	if orders[2].Items == nil || len(orders[2].Items) != 0 {
+		t.Errorf("order 3 should have an empty item list, got %#v", orders[2].Items)
+	}
+}
+
+func TestPreloadSkipsEmptyResult(t *testing.T) {
+	kq := &keysetQuerier{}
+	c := New[preloadOrder](kq, nil, mockDialect{})
+	if _, err := c.Find(context.Background(), WithPreload("Customer")); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if len(kq.sqls) != 1 {
+		t.Errorf("expected only the parent query, got %q", kq.sqls)
+	}
+}
+
+func TestPreloadRelationFieldsAreNotColumns(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}, queryRow: &mockRow{record: []any{int64(1)}}}
+	c := New[preloadOrder](mock, nil, mockDialect{})
+	if err := c.InsertOne(context.Background(), &preloadOrder{CustomerID: 10, Customer: &preloadCustomer{ID: 10}}); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if want := `INSERT INTO "orders" ("customer_id") VALUES ($1) RETURNING "id"`; mock.lastSQL != want {
+		t.Errorf("relation fields should not be inserted: %s", mock.lastSQL)
+	}
+}
+
+// nameMapper maps every field to its Go name, relations included.
+type nameMapper struct{}
+
+func (nameMapper) ColumnName(f reflect.StructField) string { return f.Name }
+
+func TestRelationFieldsSkippedForCustomMapper(t *testing.T) {
+	mock := &mockQuerier{execResult: &mockResult{rowsAffected: 1}, queryRow: &mockRow{record: []any{int64(1)}}}
+	c := New[preloadOrder](mock, nameMapper{}, mockDialect{})
+	if err := c.InsertOne(context.Background(), &preloadOrder{CustomerID: 10, Customer: &preloadCustomer{ID: 10}}); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if want := `INSERT INTO "orders" ("CustomerID") VALUES ($1) RETURNING "ID"`; mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	_, _, cols := scanTargets(reflect.ValueOf(&preloadOrder{}).Elem(), nameMapper{})
+	if want := []string{"ID", "CustomerID"}; !reflect.DeepEqual(cols, want) {
+		t.Errorf("scan columns = %v, want %v", cols, want)
+	}
+}
+
+func TestPreloadErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"Missing", `no field "Missing"`},
+		{"CustomerID", "not tagged as a relation"},
+		{"Items.Nope", `no field "Nope"`},
+	}
+	for _, tt := range tests {
+		kq := &keysetQuerier{eachQuerier: eachQuerier{batches: []*mockRows{
+			{records: [][]any{{int64(1), int64(10)}}},
+			{records: [][]any{{int64(100), int64(1), int64(7)}}},
+		}}}
+		c := New[preloadOrder](kq, nil, mockDialect{})
+		_, err := c.Find(context.Background(), WithPreload(tt.path))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.path, tt.want, err)
+		}
+	}
+}
+
+func TestPreloadRejectedByIterAndFindInto(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	c := New[preloadOrder](mock, nil, mockDialect{})
+	ctx := context.Background()
+
+	for _, err := range c.Iter(ctx, WithPreload("Customer")) {
+		if err == nil || !strings.Contains(err.Error(), "WithPreload is not supported by Iter") {
+			t.Errorf("Iter: expected a preload error, got %v", err)
+		}
+	}
+	if _, err := FindInto[preloadOrder](ctx, c, WithPreload("Customer")); err == nil || !strings.Contains(err.Error(), "not supported by FindInto") {
+		t.Errorf("FindInto: expected a preload error, got %v", err)
+	}
+	if mock.lastSQL != "" {
+		t.Errorf("no query should run, got %s", mock.lastSQL)
+	}
+}
+
+// ============================================
+// Embedded Struct Tests
+// ============================================
+
+type EmbedBase struct {
+	ID          int64     `json:"id"`
+	CreatedDate time.Time `json:"created_date"`
+	DeletedDate *string   `json:"deleted_date"`
+}
+
+type embedAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type embedCustomer struct {
+	EmbedBase
+	Name    string       `json:"name"`
+	Address embedAddress `curd:"embedded;prefix:addr_"`
+}
+
+func (embedCustomer) TableName() string { return "customers" }
+
+func TestEmbeddedColumns(t *testing.T) {
+	got := columnsFromType(reflect.TypeFor[embedCustomer](), defaultFieldMapper{})
+	want := []string{"id", "created_date", "deleted_date", "name", "addr_street", "addr_city"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+	if pk := primaryKeyFields(reflect.TypeFor[embedCustomer](), defaultFieldMapper{}); len(pk) != 1 || pk[0].column != "id" {
+		t.Errorf("expected embedded ID as primary key, got %+v", pk)
+	}
+	if typ := ColumnTypes(reflect.TypeFor[embedCustomer](), nil)["addr_city"]; typ != reflect.TypeFor[string]() {
+		t.Errorf("ColumnTypes[addr_city] = %v, want string", typ)
+	}
+}
+
+func TestEmbeddedInsertAndFind(t *testing.T) {
+	mock := &mockQuerier{
+		queryRow:   &mockRow{record: []any{int64(7)}},
+		execResult: &mockResult{rowsAffected: 1},
+	}
+	c := New[embedCustomer](mock, nil, mockDialect{})
+
+	row := &embedCustomer{Name: "ann", Address: embedAddress{Street: "1 Main St", City: "Oslo"}}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	want := `INSERT INTO "customers" ("created_date","deleted_date","name","addr_street","addr_city") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if row.ID != 7 || row.CreatedDate.IsZero() {
+		t.Errorf("expected ID and CreatedDate set through the embedding, got %+v", row.EmbedBase)
+	}
+
+	mock.queryRows = &mockRows{records: [][]any{{int64(7), nil, nil, "ann", "1 Main St", "Oslo"}}}
+	list, err := c.Find(context.Background(), WithWhere(Eq("addr_city", "Oslo")))
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	want = `SELECT "id","created_date","deleted_date","name","addr_street","addr_city" FROM "customers" WHERE addr_city = $1 AND "deleted_date" IS NULL`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if len(list) != 1 || list[0].ID != 7 || list[0].Address.City != "Oslo" {
+		t.Errorf("unexpected rows: %+v", list)
+	}
+
+	if err := c.UpdateByID(context.Background(), 7, map[string]any{"Street": "2 Side St"}); err != nil {
+		t.Fatalf("UpdateByID error: %v", err)
+	}
+	if !strings.HasPrefix(mock.lastSQL, `UPDATE "customers" SET "addr_street" = $1`) {
+		t.Errorf("unexpected SQL: %s", mock.lastSQL)
+	}
+}
+
+type embedAudit struct {
+	Name      string `json:"audit_name"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+type embedOwner struct {
+	UpdatedBy string `json:"owner"`
+}
+
+type embedPromoted struct {
+	*EmbedBase
+	embedAudit
+	embedOwner
+	Name string `json:"name"`
+}
+
+func (embedPromoted) TableName() string { return "promoted" }
+
+func TestEmbeddedPromotionRules(t *testing.T) {
+	got := columnsFromType(reflect.TypeFor[embedPromoted](), defaultFieldMapper{})
+	// Name hides embedAudit.Name; the two UpdatedBy fields hide each other.
+	want := []string{"id", "created_date", "deleted_date", "name"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(3), nil, nil, "bob"}}}}
+	c := New[embedPromoted](mock, nil, mockDialect{})
+	list, err := c.Find(context.Background())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if len(list) != 1 || list[0].EmbedBase == nil || list[0].ID != 3 {
+		t.Fatalf("expected the embedded pointer to be allocated, got %+v", list)
+	}
+
+	type hidden struct {
+		*EmbedBase
+		Name string `json:"name"`
+	}
+	type hiddenRow struct {
+		*hidden
+		Code string `json:"code"`
+	}
+	if cols := columnsFromType(reflect.TypeFor[hiddenRow](), defaultFieldMapper{}); !reflect.DeepEqual(cols, []string{"code"}) {
+		t.Errorf("unexported embedded pointers should be ignored, got %v", cols)
+	}
+}
+
+func TestEmbeddedHasFieldAndSetNow(t *testing.T) {
+	if !hasField(embedCustomer{}, "CreatedDate") || !hasField(embedCustomer{}, "City") {
+		t.Error("hasField should see fields of embedded structs")
+	}
+	if hasField(embedPromoted{}, "UpdatedBy") {
+		t.Error("ambiguous promoted field should be hidden")
+	}
+	var row embedCustomer
+	setNow(reflect.ValueOf(&row), "CreatedDate")
+	if row.CreatedDate.IsZero() {
+		t.Error("setNow should stamp the embedded field")
+	}
+}
+
+// ============================================
+// Projection Tests
+// ============================================
+
+// namedRows reports its column names, like *sql.Rows.
+type namedRows struct {
+	mockRows
+	cols []string
+}
+
+func (r *namedRows) Columns() ([]string, error) { return r.cols, nil }
+
+type orderView struct {
+	ID     int64   `json:"id"`
+	Amount float64 `json:"amount"`
+	Label  string  `json:"label"`
+	Region string  `json:"region"`
+}
+
+func TestFindIntoJoin(t *testing.T) {
+	mock := &mockQuerier{queryRows: &namedRows{
+		// Columns arrive in a different order than R's fields, with an extra one.
+		cols:     []string{"region", "id", "extra", "label", "amount"},
+		mockRows: mockRows{records: [][]any{{"north", int64(1), "x", "vip", float64(9.5)}}},
+	}}
+	c := New[orderRow](mock, nil, mockDialect{})
+
+	got, err := FindInto[orderView](context.Background(), c,
+		WithJoins(JoinClause{Type: LeftJoin, Table: "regions AS r", On: "r.id = orders.customer_id"}),
+		WithColumns("orders.id", "amount", "r.label", "r.name AS region"),
+		WithWhere(Gt("amount", 5)),
+		WithOrder(Asc("r.label"), Desc("region")),
+		WithLimit(10),
+	)
+	if err != nil {
+		t.Fatalf("FindInto error: %v", err)
+	}
+	want := `SELECT "orders"."id","amount","r"."label","r"."name" AS "region" FROM "orders" LEFT JOIN regions AS r ON r.id = orders.customer_id` +
+		` WHERE amount > $1 AND "orders"."deleted_date" IS NULL ORDER BY "r"."label" ASC, "region" DESC LIMIT $2`
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	wantRows := []orderView{{ID: 1, Amount: 9.5, Label: "vip", Region: "north"}}
+	if !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("got %+v, want %+v", got, wantRows)
+	}
+}
+
+func TestFindIntoByColumnNamesThroughWrappers(t *testing.T) {
+	// Error translation and a Router both wrap the Rows; the result column
+	// names must still reach FindInto.
+	rows := func() *namedRows {
+		return &namedRows{
+			cols:     []string{"amount", "id"},
+			mockRows: mockRows{records: [][]any{{float64(9.5), int64(1)}}},
+		}
+	}
+	type amountView struct {
+		ID     int64   `json:"id"`
+		Amount float64 `json:"amount"`
+	}
+	want := []amountView{{ID: 1, Amount: 9.5}}
+
+	c := New[orderRow](&mockQuerier{queryRows: rows()}, nil, translatingDialect{})
+	got, err := FindInto[amountView](context.Background(), c)
+	if err != nil {
+		t.Fatalf("FindInto error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("translating dialect: got %+v, want %+v", got, want)
+	}
+
+	r := NewRouter(&mockQuerier{}, []Querier{&mockQuerier{queryRows: rows()}})
+	got, err = FindInto[amountView](context.Background(), New[orderRow](r, nil, translatingDialect{}))
+	if err != nil {
+		t.Fatalf("FindInto error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("router: got %+v, want %+v", got, want)
+	}
+}
+
+func TestFindIntoDefaultColumns(t *testing.T) {
+	type amountView struct {
+		ID     int64   `json:"id"`
+		Amount float64 `json:"amount"`
+	}
+	mock := &mockQuerier{queryRows: &mockRows{records: [][]any{{int64(2), float64(3)}}}}
+	c := New[orderRow](mock, nil, mockPositionalDialect{})
+
+	got, err := FindInto[amountView](context.Background(), c,
+		WithJoins(JoinClause{Type: InnerJoin, Table: "customers AS cu", On: "cu.id = orders.customer_id"}))
+	if err != nil {
+		t.Fatalf("FindInto error: %v", err)
+	}
+	want := "SELECT `orders`.`id`,`orders`.`amount` FROM `orders` INNER JOIN customers AS cu ON cu.id = orders.customer_id" +
+		" WHERE `orders`.`deleted_date` IS NULL"
+	if mock.lastSQL != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", mock.lastSQL, want)
+	}
+	if len(got) != 1 || got[0] != (amountView{ID: 2, Amount: 3}) {
+		t.Errorf("unexpected rows: %+v", got)
+	}
+}
+
+func TestFindPaginatedInto(t *testing.T) {
+	mock := &mockQuerier{
+		queryRow:  &mockRow{record: []any{int64(42)}},
+		queryRows: &mockRows{records: [][]any{{int64(1), "paid"}}},
+	}
+	c := New[orderRow](mock, nil, mockDialect{})
+
+	type statusView struct {
+		ID    int64  `json:"id"`
+		State string `json:"state"`
+	}
+	page, err := FindPaginatedInto[*statusView](context.Background(), c,
+		WithColumns("id", "status AS state"), WithLimit(1))
+	if err != nil {
+		t.Fatalf("FindPaginatedInto error: %v", err)
+	}
+	if page.Total != 42 || len(page.List) != 1 || *page.List[0] != (statusView{1, "paid"}) {
+		t.Errorf("unexpected page: total %d, list %+v", page.Total, page.List)
+	}
+}
+
+func TestFindIntoErrors(t *testing.T) {
+	c := New[orderRow](&mockQuerier{queryRows: &mockRows{}}, nil, mockDialect{})
+	ctx := context.Background()
+
+	if _, err := FindInto[orderView](ctx, c); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("R column missing from T: expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := FindInto[orderView](ctx, c, WithColumns("id", "r.password")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("column of neither T nor R: expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := FindInto[orderView](ctx, c, WithColumns("id", "lower(status) AS label")); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("expression: expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := FindInto[orderView](ctx, c, WithColumns("status AS x.y")); err == nil {
+		t.Error("expected error for qualified alias")
+	}
+}
+
+// ============================================
+// Row Lock Tests
+// ============================================
+
+func TestFindWithLock(t *testing.T) {
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	tx := &mockTx{Querier: mock}
+	var got string
+	err := WithTx(context.Background(), &mockTxBeginner{tx: tx}, func(ctx context.Context, tx Querier) error {
+		c := New[testTable](mock, nil, mockDialect{}).WithQuerier(tx)
+		_, err := c.Find(ctx,
+			WithWhere(Eq("name", "pending")),
+			WithOrder(Asc("id")),
+			WithLimit(10),
+			WithLock(ForUpdate, SkipLocked),
+		)
+		got = mock.lastSQL
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	want := `SELECT "id","name","age","created_date","deleted_date" FROM "test_table" WHERE name = $1 AND "deleted_date" IS NULL` +
+		` ORDER BY "id" ASC LIMIT $2 FOR UPDATE SKIP LOCKED`
+	if got != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestWithLockClauses(t *testing.T) {
+	tests := []struct {
+		opt  FindOption
+		want string
+	}{
+		{WithLock(ForUpdate), " FOR UPDATE"},
+		{WithLock(ForShare), " FOR SHARE"},
+		{WithLock(ForUpdate, NoWait), " FOR UPDATE NOWAIT"},
+		{WithLock(ForShare, SkipLocked), " FOR SHARE SKIP LOCKED"},
+	}
+	for _, tt := range tests {
+		mock := &mockQuerier{queryRows: &mockRows{}}
+		c := New[testTable](&mockTx{Querier: mock}, nil, mockPositionalDialect{})
+		if _, err := c.Find(context.Background(), WithLimit(1), WithOffset(2), tt.opt); err != nil {
+			t.Fatalf("Find error: %v", err)
+		}
+		if !strings.HasSuffix(mock.lastSQL, "LIMIT ? OFFSET ?"+tt.want) {
+			t.Errorf("SQL %q does not end with %q", mock.lastSQL, tt.want)
+		}
+	}
+}
+
+func TestWithLockErrors(t *testing.T) {
+	ctx := context.Background()
+	mock := &mockQuerier{queryRows: &mockRows{}}
+	if _, err := New[testTable](mock, nil, mockDialect{}).Find(ctx, WithLock(ForUpdate)); !errors.Is(err, ErrLockWithoutTx) {
+		t.Errorf("expected ErrLockWithoutTx, got %v", err)
+	}
+	if mock.lastSQL != "" {
+		t.Errorf("no query should run, got %s", mock.lastSQL)
+	}
+
+	c := New[orderRow](&mockTx{Querier: mock}, nil, mockDialect{})
+	if _, err := c.Find(ctx, WithLock(ForUpdate, SkipLocked, NoWait)); err == nil {
+		t.Error("expected error for SkipLocked with NoWait")
+	}
+	if _, err := c.Find(ctx, WithLock("NO KEY UPDATE; DROP")); err == nil {
+		t.Error("expected error for invalid strength")
+	}
+	if _, err := Aggregate[statusTotal](ctx, c, WithGroupBy("status"), WithLock(ForUpdate)); err == nil {
+		t.Error("expected Aggregate to reject WithLock")
+	}
+}
+
+// ============================================
+// Context Transaction Tests
+// ============================================
+
+// txLog records every statement run on it, for asserting savepoints. Exec
+// fails for statements starting with failOn, if set.
+type txLog struct {
+	mockQuerier
+	sqls   []string
+	failOn string
+}
+
+func (l *txLog) Exec(ctx context.Context, sql string, args ...any) (Result, error) {
+	l.sqls = append(l.sqls, sql)
+	if l.failOn != "" && strings.HasPrefix(sql, l.failOn) {
+		return nil, errors.New("connection lost")
+	}
+	return l.mockQuerier.Exec(ctx, sql, args...)
+}
+
+// mockPool is a Querier that begins transactions, like *postgres.Pool.
+type mockPool struct {
+	*mockQuerier
+	*mockTxBeginner
+}
+
+func newMockPool() (*mockPool, *txLog, *mockTx) {
+	log := &txLog{mockQuerier: mockQuerier{
+		execResult: &mockResult{rowsAffected: 1},
+		queryRow:   &mockRow{record: []any{int64(3)}},
+		queryRows:  &mockRows{},
+	}}
+	tx := &mockTx{Querier: log}
+	pool := &mockPool{mockQuerier: &mockQuerier{queryRow: &mockRow{record: []any{int64(0)}}}, mockTxBeginner: &mockTxBeginner{tx: tx}}
+	return pool, log, tx
+}
+
+func TestWithTxStoresTxInContext(t *testing.T) {
+	pool, log, tx := newMockPool()
+	c := New[testTable](pool, nil, mockDialect{})
+
+	err := WithTx(context.Background(), pool, func(ctx context.Context, _ Querier) error {
+		n, err := c.Count(ctx, nil)
+		if err != nil || n != 3 {
+			t.Errorf("Count in tx = %d, %v; want 3 from the transaction", n, err)
+		}
+		if ContextQuerier(ctx, pool) != tx {
+			t.Error("ContextQuerier should return the transaction")
+		}
+		_, err = c.Find(ctx, WithLock(ForUpdate))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx error: %v", err)
+	}
+	if !tx.committed || log.lastSQL == "" || pool.mockQuerier.lastSQL != "" {
+		t.Errorf("expected queries on the transaction only, pool ran %q", pool.mockQuerier.lastSQL)
+	}
+
+	if n, _ := c.Count(context.Background(), nil); n != 0 {
+		t.Errorf("Count outside tx = %d, want 0 from the pool", n)
+	}
+}
+
+func TestWithTxContextIgnoresOtherDatabases(t *testing.T) {
+	pool, _, _ := newMockPool()
+	other, _, _ := newMockPool()
+	c := New[testTable](other, nil, mockDialect{})
+
+	err := WithTx(context.Background(), pool, func(ctx context.Context, _ Querier) error {
+		if ContextQuerier(ctx, other) != Querier(other) {
+			t.Error("a transaction on pool must not be used for other")
+		}
+		_, err := c.Find(ctx, WithLock(ForUpdate))
+		if !errors.Is(err, ErrLockWithoutTx) {
+			t.Errorf("expected ErrLockWithoutTx on other, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx error: %v", err)
+	}
+}
+
+func TestNestedWithTxUsesSavepoints(t *testing.T) {
+	pool, log, tx := newMockPool()
+	begins := 0
+	b := &countingBeginner{mockPool: pool, begins: &begins}
+	innerErr := errors.New("inner failed")
+
+	err := WithTx(context.Background(), b, func(ctx context.Context, outer Querier) error {
+		err := WithTx(ctx, b, func(ctx context.Context, inner Querier) error {
+			if inner != outer {
+				t.Error("nested WithTx should run on the enclosing transaction")
+			}
+			return WithTx(ctx, b, func(context.Context, Querier) error { return nil })
+		})
+		if err != nil {
+			return err
+		}
+		if err := WithTx(ctx, b, func(context.Context, Querier) error { return innerErr }); !errors.Is(err, innerErr) {
+			t.Errorf("expected the inner error, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx error: %v", err)
+	}
+	if begins != 1 || !tx.committed || tx.rolledBack {
+		t.Errorf("expected one committed transaction, got %d begins, committed=%v", begins, tx.committed)
+	}
+	want := []string{
+		"SAVEPOINT curd_sp_1",
+		"SAVEPOINT curd_sp_2",
+		"RELEASE SAVEPOINT curd_sp_2",
+		"RELEASE SAVEPOINT curd_sp_1",
+		"SAVEPOINT curd_sp_1",
+		"ROLLBACK TO SAVEPOINT curd_sp_1",
+		"RELEASE SAVEPOINT curd_sp_1",
+	}
+	if !reflect.DeepEqual(log.sqls, want) {
+		t.Errorf("unexpected savepoints:\n got: %q\nwant: %q", log.sqls, want)
+	}
+}
+
+func TestNestedWithTxPanicRollsBackToSavepoint(t *testing.T) {
+	pool, log, tx := newMockPool()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected the panic to propagate")
+		}
+		if n := len(log.sqls); !tx.rolledBack || n < 2 || log.sqls[n-2] != "ROLLBACK TO SAVEPOINT curd_sp_1" || log.sqls[n-1] != "RELEASE SAVEPOINT curd_sp_1" {
+			t.Errorf("expected savepoint and transaction rolled back, got %q", log.sqls)
+		}
+	}()
+	_ = WithTx(context.Background(), pool, func(ctx context.Context, _ Querier) error {
+		return WithTx(ctx, pool, func(context.Context, Querier) error { panic("boom") })
+	})
+}
+
+func TestNestedWithTxJoinsSavepointRollbackError(t *testing.T) {
+	pool, log, _ := newMockPool()
+	log.failOn = "ROLLBACK TO SAVEPOINT"
+	innerErr := errors.New("inner failed")
+
+	err := WithTx(context.Background(), pool, func(ctx context.Context, _ Querier) error {
+		return WithTx(ctx, pool, func(context.Context, Querier) error { return innerErr })
+	})
+	if !errors.Is(err, innerErr) || !strings.Contains(err.Error(), "rollback to savepoint: connection lost") {
+		t.Errorf("expected the inner and rollback errors, got %v", err)
+	}
+}
+
+func TestWithTxJoinsThroughUnwrap(t *testing.T) {
+	pool, _, tx := newMockPool()
+	wrapped := &unwrappingBeginner{pool: pool}
+	err := WithTx(context.Background(), wrapped, func(ctx context.Context, _ Querier) error {
+		if ContextQuerier(ctx, pool) != tx {
+			t.Error("Curds on pool should join a transaction begun through its wrapper")
+		}
+		return nil
+	})
+	if err != nil || !wrapped.begun {
+		t.Fatalf("WithTx error: %v, begun %v", err, wrapped.begun)
+	}
+}
+
+// unwrappingBeginner begins transactions on pool, like a beginner setting
+// transaction options.
+type unwrappingBeginner struct {
+	pool  *mockPool
+	begun bool
+}
+
+func (b *unwrappingBeginner) Begin(ctx context.Context) (Tx, error) {
+	b.begun = true
+	return b.pool.Begin(ctx)
+}
+
+func (b *unwrappingBeginner) Unwrap() TxBeginner { return b.pool }
+
+type countingBeginner struct {
+	*mockPool
+	begins *int
+}
+
+func (b *countingBeginner) Begin(ctx context.Context) (Tx, error) {
+	*b.begins++
+	return b.mockPool.Begin(ctx)
+}
+
+// ============================================
+// Transaction Retry Tests
+// ============================================
+
+// flakyBeginner starts transactions whose commit fails with the driver
+// error conflict, translated onto ErrSerializationFailure, for the first
+// failures transactions.
+type flakyBeginner struct {
+	failures int
+	begins   int
+	conflict error
+}
+
+func (b *flakyBeginner) Begin(context.Context) (Tx, error) {
+	b.begins++
+	tx := &mockTx{Querier: &mockQuerier{}}
+	if b.begins <= b.failures {
+		tx.commitErr = b.conflict
+	}
+	return tx, nil
+}
+
+func (b *flakyBeginner) TranslateError(err error) error {
+	if errors.Is(err, b.conflict) {
+		return &DBError{Kind: ErrSerializationFailure, Err: err}
+	}
+	return err
+}
+
+func TestWithTxRetry(t *testing.T) {
+	b := &flakyBeginner{failures: 2, conflict: errors.New("40001")}
+	var attempts []int
+	var delays []time.Duration
+	calls := 0
+	err := WithTxRetry(context.Background(), b, TxRetryOptions{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnRetry: func(_ context.Context, attempt int, err error, delay time.Duration) {
+			attempts = append(attempts, attempt)
+			delays = append(delays, delay)
+		},
+	}, func(context.Context, Querier) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxRetry error: %v", err)
+	}
+	if calls != 3 || !reflect.DeepEqual(attempts, []int{1, 2}) {
+		t.Errorf("expected 3 runs and retries after attempts 1 and 2, got %d runs, %v", calls, attempts)
+	}
+	if delays[0] < time.Millisecond || delays[0] >= 1500*time.Microsecond ||
+		delays[1] < 2*time.Millisecond || delays[1] >= 3*time.Millisecond {
+		t.Errorf("unexpected backoff delays %v", delays)
+	}
+}
+
+func TestWithTxRetryGivesUp(t *testing.T) {
+	b := &flakyBeginner{failures: 5, conflict: errors.New("40001")}
+	err := WithTxRetry(context.Background(), b, TxRetryOptions{MaxAttempts: 2, BaseDelay: time.Microsecond},
+		func(context.Context, Querier) error { return nil })
+	if !errors.Is(err, b.conflict) || b.begins != 2 {
+		t.Errorf("expected the conflict after 2 attempts, got %v after %d", err, b.begins)
+	}
+
+	// Errors that are not transaction conflicts are not retried.
+	b = &flakyBeginner{}
+	fnErr := fmt.Errorf("insert: %w", &DBError{Kind: ErrUniqueViolation})
+	err = WithTxRetry(context.Background(), b, TxRetryOptions{}, func(context.Context, Querier) error { return fnErr })
+	if !errors.Is(err, fnErr) || b.begins != 1 {
+		t.Errorf("expected no retry, got %v after %d attempts", err, b.begins)
+	}
+
+	// Deadlocks reported by fn are retried.
+	b = &flakyBeginner{}
+	calls := 0
+	err = WithTxRetry(context.Background(), b, TxRetryOptions{BaseDelay: time.Microsecond}, func(context.Context, Querier) error {
+		if calls++; calls == 1 {
+			return &DBError{Kind: ErrDeadlock}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected a retry after the deadlock, got %v after %d", err, calls)
+	}
+}
+
+func TestWithTxRetryStopsOnContextDone(t *testing.T) {
+	b := &flakyBeginner{failures: 5, conflict: errors.New("40001")}
+	ctx, cancel := context.WithCancel(context.Background())
+	err := WithTxRetry(ctx, b, TxRetryOptions{
+		MaxAttempts: 5,
+		BaseDelay:   time.Hour,
+		OnRetry:     func(context.Context, int, error, time.Duration) { cancel() },
+	}, func(context.Context, Querier) error { return nil })
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, b.conflict) || b.begins != 1 {
+		t.Errorf("expected the conflict and context.Canceled after 1 attempt, got %v after %d", err, b.begins)
+	}
+}
+
+func TestWithTxRetryNestedRunsOnce(t *testing.T) {
+	pool, log, _ := newMockPool()
+	calls := 0
+	err := WithTx(context.Background(), pool, func(ctx context.Context, _ Querier) error {
+		return WithTxRetry(ctx, pool, TxRetryOptions{BaseDelay: time.Microsecond}, func(context.Context, Querier) error {
+			calls++
+			return &DBError{Kind: ErrSerializationFailure}
+		})
+	})
+	if !errors.Is(err, ErrSerializationFailure) || calls != 1 {
+		t.Errorf("expected one run in the enclosing transaction, got %v after %d", err, calls)
+	}
+	if len(log.sqls) != 3 || log.sqls[0] != "SAVEPOINT curd_sp_1" {
+		t.Errorf("expected a savepoint, got %q", log.sqls)
+	}
+}
+
+// ============================================
+// Router Tests
+// ============================================
+
+func newTestRouter(replicas int, opts ...RouterOption) (*Router, *mockPool, []*mockQuerier) {
+	primary, _, _ := newMockPool()
+	primary.mockQuerier.queryRows = &mockRows{}
+	primary.mockQuerier.queryRow = &mockRow{record: []any{int64(1)}}
+	primary.mockQuerier.execResult = &mockResult{rowsAffected: 1}
+	var qs []Querier
+	var mocks []*mockQuerier
+	for range replicas {
+		m := &mockQuerier{queryRows: &mockRows{}, queryRow: &mockRow{record: []any{int64(1)}}}
+		qs = append(qs, m)
+		mocks = append(mocks, m)
+	}
+	return NewRouter(primary, qs, opts...), primary, mocks
+}
+
+func TestRouterSplitsReadsAndWrites(t *testing.T) {
+	r, primary, replicas := newTestRouter(2)
+	c := New[testTable](r, nil, mockDialect{})
+	ctx := context.Background()
+
+	if _, err := c.Find(ctx); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if _, err := c.Count(ctx, nil); err != nil {
+		t.Fatalf("Count error: %v", err)
+	}
+	if replicas[0].lastSQL == "" || replicas[1].lastSQL == "" || primary.mockQuerier.lastSQL != "" {
+		t.Errorf("expected reads on both replicas in turn, primary ran %q", primary.mockQuerier.lastSQL)
+	}
+
+	replicas[0].lastSQL, replicas[1].lastSQL = "", ""
+	if err := c.InsertOne(ctx, &testTable{Name: "a"}); err != nil {
+		t.Fatalf("InsertOne error: %v", err)
+	}
+	if !strings.HasPrefix(primary.mockQuerier.lastSQL, `INSERT INTO "test_table"`) {
+		t.Errorf("expected INSERT ... RETURNING on the primary, got %q", primary.mockQuerier.lastSQL)
+	}
+	if _, err := c.InsertIgnore(ctx, &testTable{Name: "a"}, []string{"name"}); err != nil {
+		t.Fatalf("InsertIgnore error: %v", err)
+	}
+	if !strings.HasSuffix(primary.mockQuerier.lastSQL, `DO NOTHING RETURNING "id"`) {
+		t.Errorf("expected INSERT ... ON CONFLICT on the primary, got %q", primary.mockQuerier.lastSQL)
+	}
+	if replicas[0].lastSQL != "" || replicas[1].lastSQL != "" {
+		t.Errorf("no write should reach a replica, got %q and %q", replicas[0].lastSQL, replicas[1].lastSQL)
+	}
+	if _, err := r.Exec(ctx, "DELETE FROM test_table"); err != nil {
+		t.Fatalf("Exec error: %v", err)
+	}
+	if primary.mockQuerier.lastSQL != "DELETE FROM test_table" {
+		t.Errorf("expected writes on the primary, got %q", primary.mockQuerier.lastSQL)
+	}
+
+	replicas[0].lastSQL = ""
+	if _, err := c.Find(UsePrimary(ctx), WithWhere(Eq("name", "a"))); err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !strings.Contains(primary.mockQuerier.lastSQL, "name = $1") || replicas[0].lastSQL != "" {
+		t.Errorf("expected UsePrimary to read from the primary, got %q", primary.mockQuerier.lastSQL)
+	}
+}
+
+func TestRouterTransactionsUsePrimary(t *testing.T) {
+	r, _, replicas := newTestRouter(1)
+	_, log, tx := newMockPool()
+	r.primary = &mockPool{mockQuerier: &mockQuerier{}, mockTxBeginner: &mockTxBeginner{tx: tx}}
+	c := New[testTable](r, nil, mockDialect{})
+
+	err := WithTx(context.Background(), r, func(ctx context.Context, _ Querier) error {
+		_, err := c.Find(ctx, WithLock(ForUpdate))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx error: %v", err)
+	}
+	if !tx.committed || !strings.HasSuffix(log.lastSQL, "FOR UPDATE") || replicas[0].lastSQL != "" {
+		t.Errorf("expected the read in the primary's transaction, got %q", log.lastSQL)
+	}
+
+	noTx := NewRouter(&mockQuerier{}, nil)
+	if _, err := noTx.Begin(context.Background()); !errors.Is(err, ErrNoTxBeginner) {
+		t.Errorf("expected ErrNoTxBeginner, got %v", err)
+	}
+}
+
+func TestRouterLeastConnections(t *testing.T) {
+	r, _, replicas := newTestRouter(2, WithReplicaPolicy(LeastConnections))
+	ctx := context.Background()
+
+	held, err := r.Query(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatalf("Query error: %v", err)
+	}
+	// replicas[0] has an open read, so the next reads go to replicas[1].
+	for range 3 {
+		replicas[1].lastSQL = ""
+		var n int64
+		if err := r.QueryRow(ctx, "SELECT 2").Scan(&n); err != nil {
+			t.Fatalf("Scan error: %v", err)
+		}
+		if replicas[1].lastSQL != "SELECT 2" {
+			t.Fatal("expected reads on the idle replica")
+		}
+	}
+	// An unscanned Row holds nothing open.
+	_ = r.QueryRow(ctx, "SELECT 3")
+	held.Close()
+	held.Close()
+	if n := r.replicas[0].open.Load() + r.replicas[1].open.Load(); n != 0 {
+		t.Errorf("expected no open reads, got %d", n)
+	}
+}
+
+func TestRouterEjectsUnhealthyReplicas(t *testing.T) {
+	down := map[Querier]bool{} // only written between probes
+	r, primary, replicas := newTestRouter(2, WithHealthCheck(time.Hour, func(_ context.Context, q Querier) error {
+		if down[q] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+	defer r.Close()
+	ctx := context.Background()
+
+	down[replicas[0]] = true
+	r.checkReplicas(ctx)
+	for range 2 {
+		if _, err := r.Query(ctx, "SELECT 1"); err != nil {
+			t.Fatalf("Query error: %v", err)
+		}
+	}
+	if replicas[0].lastSQL != "" || replicas[1].lastSQL != "SELECT 1" {
+		t.Error("expected reads only on the healthy replica")
+	}
+
+	down[replicas[1]] = true
+	r.checkReplicas(ctx)
+	if _, err := r.Query(ctx, "SELECT 2"); err != nil {
+		t.Fatalf("Query error: %v", err)
+	}
+	if primary.mockQuerier.lastSQL != "SELECT 2" {
+		t.Error("expected reads on the primary with every replica ejected")
+	}
+
+	down[replicas[0]] = false
+	r.checkReplicas(ctx)
+	if _, err := r.Query(ctx, "SELECT 3"); err != nil {
+		t.Fatalf("Query error: %v", err)
+	}
+	if replicas[0].lastSQL != "SELECT 3" {
+		t.Error("expected the restored replica to serve reads")
+	}
+}
+
+func TestRouterKeepsColumnRows(t *testing.T) {
+	primary := &mockQuerier{}
+	replica := &mockQuerier{queryRows: &namedRows{cols: []string{"id"}}}
+	r := NewRouter(primary, []Querier{replica})
+	rows, err := r.Query(context.Background(), "SELECT id FROM orders")
+	if err != nil {
+		t.Fatalf("Query error: %v", err)
+	}
+	if cr, ok := rows.(ColumnRows); !ok {
+		t.Error("expected the routed rows to report their columns")
+	} else if cols, _ := cr.Columns(); !reflect.DeepEqual(cols, []string{"id"}) {
+		t.Errorf("unexpected columns %v", cols)
+	}
+
+	replica.queryRows = &mockRows{}
+	rows, _ = r.Query(context.Background(), "SELECT 1")
+	if _, ok := rows.(ColumnRows); ok {
+		t.Error("rows without columns must not gain ColumnRows")
+	}
+}
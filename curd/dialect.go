@@ -1,5 +1,70 @@
 package curd
 
+import "strings"
+
+// Dialect abstracts the SQL syntax differences between database engines.
+// Implementations live in sub-packages (e.g. curd/postgres, curd/mysql).
 type Dialect interface {
+	// Placeholder returns the bind parameter marker for the n-th (1-based)
+	// argument, e.g. "$1" for PostgreSQL or "?" for MySQL.
 	Placeholder(n int) string
+
+	// QuoteIdent quotes a table or column identifier. Implementations should
+	// leave anything that is not a plain (optionally dot-qualified) identifier
+	// unchanged, so expressions such as "count(*)" or "users AS u" pass through.
+	QuoteIdent(name string) string
+
+	// SupportsReturning reports whether INSERT ... RETURNING is available.
+	// When false, InsertOne reads the generated id from the Result instead
+	// (see LastInsertIDResult).
+	SupportsReturning() bool
+
+	// LimitOffset renders the pagination clause (with a leading space) for
+	// the given placeholders. Either argument may be empty when absent.
+	LimitOffset(limit, offset string) string
+
+	// OnConflict renders the clause (with a leading space) appended to an
+	// INSERT statement to turn it into an upsert. conflictCols identify the
+	// unique key; updateCols are overwritten with the incoming values. An
+	// empty updateCols means "do nothing on conflict"; a dialect that needs
+	// a column for that may return "" when conflictCols is empty too, and
+	// Curd then passes the primary key. Otherwise the clause must end with
+	// its assignment list: Curd may append further ",col = expr"
+	// assignments, e.g. to increment a version column.
+	OnConflict(conflictCols, updateCols []string) string
+}
+
+// isPlainIdent reports whether s is a bare identifier, optionally qualified
+// with dots (e.g. "users" or "public.users"), that can be safely quoted.
+func isPlainIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "" {
+			return false
+		}
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && isDigit(c)) {
+				continue
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// QuoteIdentWith quotes each dot-separated part of name with the quote
+// character q. Names that are not plain identifiers are returned unchanged.
+// Dialect implementations use it to provide QuoteIdent.
+func QuoteIdentWith(name string, q byte) string {
+	if !isPlainIdent(name) {
+		return name
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = string(q) + p + string(q)
+	}
+	return strings.Join(parts, ".")
 }
@@ -0,0 +1,215 @@
+package curd
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Embedded structs are flattened into their parent, so their fields map to
+// columns of the parent's table:
+//
+//	type BaseModel struct {
+//	    ID          int64      `json:"id"`
+//	    CreatedDate time.Time  `json:"created_date"`
+//	    DeletedDate *time.Time `json:"deleted_date"`
+//	}
+//
+//	type Customer struct {
+//	    BaseModel
+//	    Name    string  `json:"name"`
+//	    Address Address `curd:"embedded;prefix:addr_"` // addr_street, addr_city
+//	}
+//
+// Anonymous struct (or pointer to struct) fields are flattened unless their
+// json or gorm tag names a column, or their type is a time.Time,
+// sql.Scanner or driver.Valuer. Anonymous pointers to unexported struct
+// types are ignored, as they cannot be allocated when scanning. Named
+// fields are flattened when tagged curd:"embedded" (gorm:"embedded" also
+// works). prefix (gorm: embeddedPrefix) is prepended to the columns of the
+// embedded fields; prefixes of nested embeddings add up.
+//
+// Field names follow Go's promotion rules: a shallower field hides deeper
+// fields of the same name, and fields of the same name at the same depth
+// hide each other.
+
+// flatField is a field of a struct with its embedded structs flattened.
+type flatField struct {
+	reflect.StructField        // Index is the path from the outer struct
+	prefix              string // column prefix of the enclosing embeddings
+}
+
+// columnName maps f to its column with fm, applying the embedding prefix.
+// Relation fields have no column, whatever fm says.
+func (f flatField) columnName(fm FieldMapper) string {
+	if isRelation(f.StructField) {
+		return ""
+	}
+	col := fm.ColumnName(f.StructField)
+	if col == "" {
+		return ""
+	}
+	return f.prefix + col
+}
+
+var flatFieldCache sync.Map // reflect.Type -> []flatField
+
+// flatFields returns the exported fields of struct type t in declaration
+// order, with embedded structs flattened and hidden fields dropped. The
+// result is cached per type.
+func flatFields(t reflect.Type) []flatField {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := flatFieldCache.Load(t); ok {
+		return cached.([]flatField)
+	}
+	var all []flatField
+	collectFields(t, nil, "", map[reflect.Type]bool{t: true}, &all)
+
+	// Keep, for each name, the shallowest field if it is the only one at
+	// its depth.
+	type best struct {
+		depth, count int
+	}
+	byName := map[string]best{}
+	for _, f := range all {
+		b, ok := byName[f.Name]
+		switch {
+		case !ok || len(f.Index) < b.depth:
+			byName[f.Name] = best{len(f.Index), 1}
+		case len(f.Index) == b.depth:
+			b.count++
+			byName[f.Name] = b
+		}
+	}
+	fields := make([]flatField, 0, len(all))
+	for _, f := range all {
+		if b := byName[f.Name]; b.depth == len(f.Index) && b.count == 1 {
+			fields = append(fields, f)
+		}
+	}
+	flatFieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int, prefix string, path map[reflect.Type]bool, out *[]flatField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		f.Index = append(append([]int(nil), index...), i)
+		if isEmbedded(f) {
+			if !f.IsExported() && f.Type.Kind() == reflect.Ptr {
+				continue // cannot be allocated when scanning
+			}
+			et := derefType(f.Type)
+			if !path[et] {
+				path[et] = true
+				collectFields(et, f.Index, prefix+embedPrefix(f), path, out)
+				delete(path, et)
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		*out = append(*out, flatField{StructField: f, prefix: prefix})
+	}
+}
+
+var (
+	timeType    = reflect.TypeFor[time.Time]()
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	valuerType  = reflect.TypeFor[driver.Valuer]()
+)
+
+// isEmbedded reports whether f is flattened into its parent.
+func isEmbedded(f reflect.StructField) bool {
+	t := derefType(f.Type)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if isRelation(f) {
+		return false
+	}
+	if hasTagOption(f, "curd", "embedded") || hasTagOption(f, "gorm", "embedded") {
+		return true
+	}
+	if !f.Anonymous || t == timeType || reflect.PointerTo(t).Implements(scannerType) || t.Implements(valuerType) {
+		return false
+	}
+	if _, ok := tagValue(f, "gorm", "column"); ok {
+		return false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name == ""
+}
+
+func embedPrefix(f reflect.StructField) string {
+	if p, ok := tagValue(f, "curd", "prefix"); ok {
+		return p
+	}
+	p, _ := tagValue(f, "gorm", "embeddedPrefix")
+	return p
+}
+
+// ColumnTypes maps the columns of struct type t, embedded structs
+// flattened, to their Go field types.
+func ColumnTypes(t reflect.Type, fm FieldMapper) map[string]reflect.Type {
+	if fm == nil {
+		fm = defaultFieldMapper{}
+	}
+	types := map[string]reflect.Type{}
+	for _, f := range flatFields(t) {
+		if col := f.columnName(fm); col != "" {
+			types[col] = f.Type
+		}
+	}
+	return types
+}
+
+// fieldByName looks up the flattened field name of struct type t,
+// dereferencing pointer types.
+func fieldByName(t reflect.Type, name string) (flatField, bool) {
+	if name == "" || t == nil {
+		return flatField{}, false
+	}
+	for _, f := range flatFields(t) {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return flatField{}, false
+}
+
+// readField returns the field of struct value v at index, or the zero value
+// of typ when the path crosses a nil embedded pointer.
+func readField(v reflect.Value, index []int, typ reflect.Type) reflect.Value {
+	f, err := v.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Zero(typ)
+	}
+	return f
+}
+
+// settableField returns the field of struct value v at index, allocating
+// nil embedded pointers on the way. The result is invalid when a nil
+// pointer cannot be set.
+func settableField(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
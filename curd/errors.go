@@ -0,0 +1,160 @@
+package curd
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Sentinel errors. Match them with errors.Is; constraint violations carry
+// details in a *DBError (use errors.As).
+var (
+	// ErrNotFound is returned by FindOne and FindByID when no row matches,
+	// and by QueryRowRaw when the driver reports no rows.
+	ErrNotFound = errors.New("curd: not found")
+
+	ErrUniqueViolation      = errors.New("curd: unique violation")
+	ErrForeignKeyViolation  = errors.New("curd: foreign key violation")
+	ErrCheckViolation       = errors.New("curd: check violation")
+	ErrSerializationFailure = errors.New("curd: serialization failure")
+	ErrDeadlock             = errors.New("curd: deadlock detected")
+)
+
+// DBError is a driver error translated into one of the sentinel errors. It
+// matches Kind with errors.Is and unwraps to the original driver error.
+//
+// Usage:
+//
+//	var dbErr *curd.DBError
+//	if errors.As(err, &dbErr) && errors.Is(err, curd.ErrUniqueViolation) {
+//	    log.Printf("duplicate %s (%s)", dbErr.Column, dbErr.Constraint)
+//	}
+type DBError struct {
+	Kind       error  // one of the Err* sentinels
+	Table      string // table name, when reported
+	Constraint string // violated constraint, when reported
+	Column     string // offending column, when reported or derivable
+	Err        error  // original driver error
+}
+
+func (e *DBError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Kind.Error())
+	var details []string
+	if e.Constraint != "" {
+		details = append(details, "constraint "+e.Constraint)
+	}
+	if e.Column != "" {
+		details = append(details, "column "+e.Column)
+	}
+	if len(details) > 0 {
+		b.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	if e.Err != nil {
+		b.WriteString(": " + e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *DBError) Is(target error) bool { return target == e.Kind }
+
+func (e *DBError) Unwrap() error { return e.Err }
+
+// ErrorTranslator is implemented by Dialects or Queriers that map driver
+// errors onto curd's error taxonomy (see DBError and ErrNotFound).
+// TranslateError must return err unchanged when it does not recognise it.
+//
+// Curd consults its Dialect first, then its Querier. The standalone
+// functions (QueryRaw, ExecRaw, ...) consult the Querier.
+type ErrorTranslator interface {
+	TranslateError(err error) error
+}
+
+// translator returns the ErrorTranslator for c, or nil.
+func (c *Curd[T]) translator() ErrorTranslator {
+	if tr, ok := c.dialect.(ErrorTranslator); ok {
+		return tr
+	}
+	if tr, ok := c.q.(ErrorTranslator); ok {
+		return tr
+	}
+	return nil
+}
+
+// db returns c's Querier for ctx (see querier), wrapped so that driver
+// errors are translated when a translator is available.
+func (c *Curd[T]) db(ctx context.Context) Querier {
+	return translating(c.querier(ctx), c.translator())
+}
+
+// translating wraps q so that errors from its methods, rows and row scans
+// are passed through tr. A nil tr returns q unchanged.
+func translating(q Querier, tr ErrorTranslator) Querier {
+	if tr == nil {
+		return q
+	}
+	return &translatingQuerier{q: q, tr: tr}
+}
+
+// queryTranslator returns the ErrorTranslator implemented by q, or nil.
+func queryTranslator(q Querier) ErrorTranslator {
+	tr, _ := q.(ErrorTranslator)
+	return tr
+}
+
+func translateErr(tr ErrorTranslator, err error) error {
+	if err == nil || tr == nil {
+		return err
+	}
+	return tr.TranslateError(err)
+}
+
+type translatingQuerier struct {
+	q  Querier
+	tr ErrorTranslator
+}
+
+func (t *translatingQuerier) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	rows, err := t.q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, translateErr(t.tr, err)
+	}
+	translated := &translatingRows{Rows: rows, tr: t.tr}
+	if cr, ok := rows.(ColumnRows); ok {
+		return &translatingColumnRows{translatingRows: translated, cr: cr}, nil
+	}
+	return translated, nil
+}
+
+func (t *translatingQuerier) QueryRow(ctx context.Context, sql string, args ...any) Row {
+	return &translatingRow{row: t.q.QueryRow(ctx, sql, args...), tr: t.tr}
+}
+
+func (t *translatingQuerier) Exec(ctx context.Context, sql string, args ...any) (Result, error) {
+	res, err := t.q.Exec(ctx, sql, args...)
+	return res, translateErr(t.tr, err)
+}
+
+type translatingRows struct {
+	Rows
+	tr ErrorTranslator
+}
+
+func (r *translatingRows) Scan(dest ...any) error { return translateErr(r.tr, r.Rows.Scan(dest...)) }
+func (r *translatingRows) Err() error             { return translateErr(r.tr, r.Rows.Err()) }
+
+// translatingColumnRows is translatingRows over Rows that implement
+// ColumnRows.
+type translatingColumnRows struct {
+	*translatingRows
+	cr ColumnRows
+}
+
+func (r *translatingColumnRows) Columns() ([]string, error) { return r.cr.Columns() }
+
+type translatingRow struct {
+	row Row
+	tr  ErrorTranslator
+}
+
+func (r *translatingRow) Scan(dest ...any) error { return translateErr(r.tr, r.row.Scan(dest...)) }
@@ -18,7 +18,29 @@ import (
 
 type mockDialect struct{}
 
-func (mockDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+func (mockDialect) Placeholder(n int) string      { return fmt.Sprintf("$%d", n) }
+func (mockDialect) QuoteIdent(name string) string { return name }
+func (mockDialect) SupportsReturning() bool       { return true }
+func (mockDialect) LimitOffset(limit, offset string) string {
+	var clause string
+	if limit != "" {
+		clause += " LIMIT " + limit
+	}
+	if offset != "" {
+		clause += " OFFSET " + offset
+	}
+	return clause
+}
+func (mockDialect) OnConflict(conflictCols, updateCols []string) string {
+	if len(updateCols) == 0 {
+		return " ON CONFLICT (" + strings.Join(conflictCols, ",") + ") DO NOTHING"
+	}
+	sets := make([]string, len(updateCols))
+	for i, c := range updateCols {
+		sets[i] = c + " = EXCLUDED." + c
+	}
+	return " ON CONFLICT (" + strings.Join(conflictCols, ",") + ") DO UPDATE SET " + strings.Join(sets, ",")
+}
 
 type mockRows struct {
 	records [][]any
@@ -5,12 +5,20 @@ import (
 	"strings"
 )
 
+// FieldMapper maps a struct field to its column name; "" means the field is
+// not a column. Relation fields (see WithPreload) are never columns, so
+// mappers are not asked about them.
 type FieldMapper interface {
 	ColumnName(f reflect.StructField) string
 }
 
 type defaultFieldMapper struct{}
 
+// DefaultFieldMapper returns the FieldMapper New uses when given nil: the
+// gorm column tag, then the json tag (snake_cased), then the snake_cased
+// field name.
+func DefaultFieldMapper() FieldMapper { return defaultFieldMapper{} }
+
 func (defaultFieldMapper) ColumnName(f reflect.StructField) string {
 	// GORM column tag takes highest priority — it is the explicit DB column name.
 	if tag := f.Tag.Get("gorm"); tag != "" {
@@ -69,13 +77,9 @@ func toSnakeCase(s string) string {
 }
 
 func columnsFromType(t reflect.Type, fm FieldMapper) []string {
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
 	var cols []string
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		name := fm.ColumnName(f)
+	for _, f := range flatFields(t) {
+		name := f.columnName(fm)
 		if name == "" {
 			continue
 		}
@@ -92,21 +96,22 @@ func rowValues(v reflect.Value, fm FieldMapper, transforms ...FieldTransformer)
 		v = v.Elem()
 	}
 	t := v.Type()
+	pk := primaryKeyFields(t, fm)
 	var cols []string
 	var vals []any
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		name := fm.ColumnName(f)
+	for _, f := range flatFields(t) {
+		name := f.columnName(fm)
 		if name == "" {
 			continue
 		}
-		// Skip auto-generated ID field when its value is zero,
-		// so the database can assign a sequence value.
-		if f.Name == "ID" && v.Field(i).IsZero() {
+		fv := readField(v, f.Index, f.Type)
+		// Skip a single primary-key field when its value is zero,
+		// so the database can assign a sequence or default value.
+		if len(pk) == 1 && pk[0].name == f.Name && fv.IsZero() {
 			continue
 		}
 		cols = append(cols, name)
-		val := v.Field(i).Interface()
+		val := fv.Interface()
 		for _, t := range transforms {
 			val = t(name, val)
 		}
@@ -115,25 +120,28 @@ func rowValues(v reflect.Value, fm FieldMapper, transforms ...FieldTransformer)
 	return cols, vals
 }
 
-func scanTargets(v reflect.Value, fm FieldMapper) (targets []any, fields []reflect.Value) {
+// scanTargets returns a scan target for each mapped field of v that can be
+// set, with the field and its column name.
+func scanTargets(v reflect.Value, fm FieldMapper) (targets []any, fields []reflect.Value, columns []string) {
 	for v.Kind() == reflect.Ptr {
 		if v.IsNil() {
-			return nil, nil
+			return nil, nil, nil
 		}
 		v = v.Elem()
 	}
-	t := v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		f := v.Field(i)
-		if fm.ColumnName(t.Field(i)) == "" {
+	for _, sf := range flatFields(v.Type()) {
+		col := sf.columnName(fm)
+		if col == "" {
 			continue
 		}
-		if !f.CanAddr() {
+		f := settableField(v, sf.Index)
+		if !f.IsValid() || !f.CanAddr() {
 			continue
 		}
 		var dest any
 		targets = append(targets, &dest)
 		fields = append(fields, f)
+		columns = append(columns, col)
 	}
 	return
 }
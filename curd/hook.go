@@ -0,0 +1,142 @@
+package curd
+
+import (
+	"context"
+	"reflect"
+)
+
+// Lifecycle hooks. Curd calls these when the entity type (or a pointer to
+// it) implements them, letting validation and denormalization live on the
+// entity instead of at every call site. A hook error aborts the operation
+// and is returned wrapped; inside WithTx it therefore rolls back.
+//
+// Row-based methods (InsertOne, InsertBatch, Save, Upsert, UpdateBatch, ...)
+// pass the row itself. Predicate-based methods (UpdateByID, UpdateWhere,
+// DeleteByID, DeleteWhere, ForceDelete) have no row, so they call the
+// scoped hooks instead, with the key or predicate and the updates.
+// DeleteByID also loads the row it deletes to call BeforeDelete on it.
+//
+// BeforeUpdate is called by the update branch of Save and Upsert and by
+// UpdateBatch. When Save or Upsert inserts, BeforeInsert and AfterInsert
+// are called instead. UpdateByID and UpdateWhere call only
+// BeforeScopedUpdate, and UpsertOnConflict and UpsertBatchOnConflict only
+// the insert hooks (see UpsertOnConflict).
+
+// BeforeInserter is called before a row is inserted, after CreatedDate and
+// ChangedDate are stamped.
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context) error
+}
+
+// AfterInserter is called after a row is inserted, once a generated primary
+// key has been set back on it.
+type AfterInserter interface {
+	AfterInsert(ctx context.Context) error
+}
+
+// BeforeUpdater is called before a row is updated.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// BeforeDeleter is called by DeleteByID, soft or hard, on the row it is
+// about to delete, loaded first for the purpose. Nothing is called when no
+// row matches.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterFinder is called on every row scanned by the Find, Iter and raw query
+// methods.
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+// WriteScope identifies the rows of a predicate-based write. Key holds the
+// primary key values of UpdateByID and DeleteByID, and is nil otherwise;
+// Where matches the affected rows in both cases.
+type WriteScope struct {
+	Key   []any
+	Where Predicate
+}
+
+// BeforeScopedUpdater is called before UpdateByID and UpdateWhere with the
+// updates to be written. The receiver is a zero T: the hook sees the scope
+// and updates, not the rows.
+type BeforeScopedUpdater interface {
+	BeforeScopedUpdate(ctx context.Context, scope WriteScope, updates map[string]any) error
+}
+
+// BeforeScopedDeleter is called before rows are deleted, soft or hard, by
+// DeleteByID, DeleteWhere and ForceDelete. The receiver is a zero T, as for
+// BeforeScopedUpdater.
+type BeforeScopedDeleter interface {
+	BeforeScopedDelete(ctx context.Context, scope WriteScope) error
+}
+
+// hookTarget returns the value whose method set the hook interfaces are
+// checked against: a pointer to the entity struct.
+func hookTarget(v reflect.Value) any {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() == reflect.Ptr {
+			return hookTarget(v.Elem())
+		}
+		return v.Interface()
+	}
+	if v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
+
+func beforeInsert(ctx context.Context, v reflect.Value) error {
+	if h, ok := hookTarget(v).(BeforeInserter); ok {
+		return h.BeforeInsert(ctx)
+	}
+	return nil
+}
+
+func afterInsert(ctx context.Context, v reflect.Value) error {
+	if h, ok := hookTarget(v).(AfterInserter); ok {
+		return h.AfterInsert(ctx)
+	}
+	return nil
+}
+
+func beforeUpdate(ctx context.Context, v reflect.Value) error {
+	if h, ok := hookTarget(v).(BeforeUpdater); ok {
+		return h.BeforeUpdate(ctx)
+	}
+	return nil
+}
+
+func beforeDelete(ctx context.Context, v reflect.Value) error {
+	if h, ok := hookTarget(v).(BeforeDeleter); ok {
+		return h.BeforeDelete(ctx)
+	}
+	return nil
+}
+
+func afterFind(ctx context.Context, v reflect.Value) error {
+	if h, ok := hookTarget(v).(AfterFinder); ok {
+		return h.AfterFind(ctx)
+	}
+	return nil
+}
+
+func beforeScopedUpdate[T any](ctx context.Context, scope WriteScope, updates map[string]any) error {
+	if h, ok := hookTarget(newT[T]()).(BeforeScopedUpdater); ok {
+		return h.BeforeScopedUpdate(ctx, scope, updates)
+	}
+	return nil
+}
+
+func beforeScopedDelete[T any](ctx context.Context, scope WriteScope) error {
+	if h, ok := hookTarget(newT[T]()).(BeforeScopedDeleter); ok {
+		return h.BeforeScopedDelete(ctx, scope)
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+// Package httpfilter parses list-endpoint query strings such as
+//
+//	?status=eq:active&age=gte:30&name=ilike:foo&sort=-created_date&page=2&size=50
+//
+// into curd.FindOptions for Curd.Find and Curd.FindPaginated. Only the
+// fields, operators and sort columns declared for an entity are accepted;
+// everything else is reported as structured Errors.
+package httpfilter
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+// Op is a filter operator, written before the value: "age=gte:30".
+// A value without a known operator prefix is an Eq filter.
+type Op string
+
+const (
+	Eq     Op = "eq"    // column = value
+	Ne     Op = "ne"    // column != value
+	Gt     Op = "gt"    // column > value
+	Gte    Op = "gte"   // column >= value
+	Lt     Op = "lt"    // column < value
+	Lte    Op = "lte"   // column <= value
+	In     Op = "in"    // column IN (a, b, ...), comma-separated
+	NotIn  Op = "nin"   // column NOT IN (a, b, ...), comma-separated
+	Like   Op = "like"  // column LIKE '%value%'
+	ILike  Op = "ilike" // column ILIKE '%value%' (PostgreSQL)
+	IsNull Op = "null"  // column IS NULL ("null:true") or IS NOT NULL ("null:false")
+)
+
+var knownOps = []Op{Eq, Ne, Gt, Gte, Lt, Lte, In, NotIn, Like, ILike, IsNull}
+
+// Reserved query parameters.
+const (
+	SortParam = "sort" // comma-separated columns, "-" prefix for descending; repeats append
+	PageParam = "page" // 1-based page number
+	SizeParam = "size" // page size
+)
+
+// Default page sizes, see PageSize.
+const (
+	DefaultPageSize = 20
+	DefaultMaxSize  = 100
+)
+
+// Code classifies an Error.
+type Code string
+
+const (
+	UnknownField       Code = "unknown_field"        // parameter is not a declared field
+	OperatorNotAllowed Code = "operator_not_allowed" // operator not allowed for the field
+	InvalidValue       Code = "invalid_value"        // value does not parse as the field's type
+	NotSortable        Code = "not_sortable"         // sort column is not declared sortable
+	InvalidPage        Code = "invalid_page"         // page or size out of range
+)
+
+// Error is a rejected query parameter. It marshals to JSON for API error
+// responses.
+type Error struct {
+	Param   string `json:"param"`
+	Value   string `json:"value"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("httpfilter: %s=%s: %s", e.Param, e.Value, e.Message)
+}
+
+// Errors is every rejected parameter of a query string, in parameter
+// order. Parse returns it as its error.
+type Errors []*Error
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap lets errors.As find the individual *Error values.
+func (es Errors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Option configures a Filter.
+type Option func(*config)
+
+type fieldSpec struct {
+	column string
+	ops    []Op
+}
+
+type config struct {
+	fm            curd.FieldMapper
+	fields        []fieldSpec
+	sortable      []string
+	defaultOrder  []curd.Order
+	defaultSize   int
+	maxSize       int
+	ignoreUnknown bool
+}
+
+// Field allows filtering on column with the given operators; with none,
+// only Eq is allowed. The query parameter is the column name.
+func Field(column string, ops ...Op) Option {
+	if len(ops) == 0 {
+		ops = []Op{Eq}
+	}
+	return func(c *config) { c.fields = append(c.fields, fieldSpec{column: column, ops: ops}) }
+}
+
+// Sortable allows the sort parameter to order by columns.
+func Sortable(columns ...string) Option {
+	return func(c *config) { c.sortable = append(c.sortable, columns...) }
+}
+
+// DefaultOrder is the order used when the query has no sort parameter.
+func DefaultOrder(orders ...curd.Order) Option {
+	return func(c *config) { c.defaultOrder = orders }
+}
+
+// PageSize sets the page size used without a size parameter and the
+// largest size accepted. Defaults to DefaultPageSize and DefaultMaxSize.
+func PageSize(size, maxSize int) Option {
+	return func(c *config) { c.defaultSize, c.maxSize = size, maxSize }
+}
+
+// IgnoreUnknown skips parameters that are not declared fields instead of
+// rejecting them, for endpoints that take other parameters too.
+func IgnoreUnknown() Option {
+	return func(c *config) { c.ignoreUnknown = true }
+}
+
+// WithFieldMapper sets the FieldMapper used to resolve T's columns. It must
+// match the one the Curd was created with. Defaults to
+// curd.DefaultFieldMapper().
+func WithFieldMapper(fm curd.FieldMapper) Option {
+	return func(c *config) { c.fm = fm }
+}
+
+type field struct {
+	column string
+	typ    reflect.Type
+	ops    []Op
+}
+
+// Filter parses query strings for entity T. It is safe for concurrent use.
+type Filter[T curd.Table] struct {
+	fields        map[string]field
+	sortable      map[string]bool
+	defaultOrder  []curd.Order
+	defaultSize   int
+	maxSize       int
+	ignoreUnknown bool
+}
+
+// New builds the Filter for T. It panics if a Field or Sortable column is
+// not a column of T, as that is a programming error.
+//
+// Usage:
+//
+//	var userFilter = httpfilter.New[User](
+//	    httpfilter.Field("status", httpfilter.Eq, httpfilter.In),
+//	    httpfilter.Field("age", httpfilter.Gte, httpfilter.Lte),
+//	    httpfilter.Field("name", httpfilter.ILike),
+//	    httpfilter.Sortable("created_date", "name"),
+//	)
+//
+//	opts, err := userFilter.Parse(r.URL.Query(), curd.Eq("tenant_id", tenantID))
+//	if err != nil {
+//	    // 400 with err (an httpfilter.Errors) as JSON
+//	}
+//	page, err := users.FindPaginated(ctx, opts...)
+func New[T curd.Table](opts ...Option) *Filter[T] {
+	cfg := &config{fm: curd.DefaultFieldMapper(), defaultSize: DefaultPageSize, maxSize: DefaultMaxSize}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	types := curd.ColumnTypes(reflect.TypeFor[T](), cfg.fm)
+	f := &Filter[T]{
+		fields:        make(map[string]field, len(cfg.fields)),
+		sortable:      make(map[string]bool, len(cfg.sortable)),
+		defaultOrder:  cfg.defaultOrder,
+		defaultSize:   cfg.defaultSize,
+		maxSize:       cfg.maxSize,
+		ignoreUnknown: cfg.ignoreUnknown,
+	}
+	for _, spec := range cfg.fields {
+		typ, ok := types[spec.column]
+		if !ok {
+			panic(fmt.Sprintf("httpfilter: %s has no column %q", reflect.TypeFor[T](), spec.column))
+		}
+		f.fields[spec.column] = field{column: spec.column, typ: typ, ops: spec.ops}
+	}
+	for _, col := range cfg.sortable {
+		if _, ok := types[col]; !ok {
+			panic(fmt.Sprintf("httpfilter: %s has no column %q", reflect.TypeFor[T](), col))
+		}
+		f.sortable[col] = true
+	}
+	return f
+}
+
+// Parse turns q into FindOptions: a WithWhere AND-ing every filter
+// parameter with the base predicates, a WithOrder from the sort parameter
+// (or DefaultOrder), and WithLimit/WithOffset from page and size. Repeated
+// parameters ("age=gte:18&age=lt:65") are AND-ed.
+//
+// Every invalid parameter is reported in the returned Errors.
+func (f *Filter[T]) Parse(q url.Values, base ...curd.Predicate) ([]curd.FindOption, error) {
+	var errs Errors
+	preds := slices.Clone(base)
+
+	params := make([]string, 0, len(q))
+	for p := range q {
+		params = append(params, p)
+	}
+	slices.Sort(params)
+	for _, p := range params {
+		if p == SortParam || p == PageParam || p == SizeParam {
+			continue
+		}
+		fd, ok := f.fields[p]
+		if !ok {
+			if !f.ignoreUnknown {
+				errs = append(errs, &Error{Param: p, Value: q.Get(p), Code: UnknownField, Message: "unknown filter field"})
+			}
+			continue
+		}
+		for _, raw := range q[p] {
+			pred, err := fd.predicate(raw)
+			if err != nil {
+				err.Param = p
+				errs = append(errs, err)
+				continue
+			}
+			preds = append(preds, pred)
+		}
+	}
+
+	var opts []curd.FindOption
+	if len(preds) > 0 {
+		opts = append(opts, curd.WithWhere(curd.And(preds...)))
+	}
+
+	orders, sortErrs := f.orders(strings.Join(q[SortParam], ","))
+	errs = append(errs, sortErrs...)
+	if len(orders) > 0 {
+		opts = append(opts, curd.WithOrder(orders...))
+	}
+
+	page, err := positiveInt(q, PageParam, 1, 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	size, err := positiveInt(q, SizeParam, f.defaultSize, f.maxSize)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if size > 0 && page > math.MaxInt/size {
+		// (page-1)*size would overflow.
+		errs = append(errs, &Error{Param: PageParam, Value: q.Get(PageParam), Code: InvalidPage, Message: "page is too large"})
+	} else if size > 0 {
+		opts = append(opts, curd.WithLimit(size), curd.WithOffset((page-1)*size))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return opts, nil
+}
+
+// predicate parses one "op:value" parameter value for fd.
+func (fd field) predicate(raw string) (curd.Predicate, *Error) {
+	op, value := Eq, raw
+	if prefix, rest, ok := strings.Cut(raw, ":"); ok && slices.Contains(knownOps, Op(prefix)) {
+		op, value = Op(prefix), rest
+	}
+	if !slices.Contains(fd.ops, op) {
+		return nil, &Error{Value: raw, Code: OperatorNotAllowed, Message: fmt.Sprintf("operator %q is not allowed", op)}
+	}
+	invalid := func(err error) *Error {
+		return &Error{Value: raw, Code: InvalidValue, Message: err.Error()}
+	}
+
+	switch op {
+	case IsNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalid(fmt.Errorf("expected true or false"))
+		}
+		if isNull {
+			return curd.IsNull(fd.column), nil
+		}
+		return curd.IsNotNull(fd.column), nil
+	case Like, ILike:
+		pattern := "%" + escapeLike(value) + "%"
+		if op == Like {
+			return curd.Like(fd.column, pattern), nil
+		}
+		return curd.ILike(fd.column, pattern), nil
+	case In, NotIn:
+		parts := strings.Split(value, ",")
+		vals := make([]any, len(parts))
+		for i, p := range parts {
+			v, err := convert(p, fd.typ)
+			if err != nil {
+				return nil, invalid(err)
+			}
+			vals[i] = v
+		}
+		if op == In {
+			return curd.In(fd.column, vals...), nil
+		}
+		return curd.NotIn(fd.column, vals...), nil
+	}
+
+	v, err := convert(value, fd.typ)
+	if err != nil {
+		return nil, invalid(err)
+	}
+	switch op {
+	case Ne:
+		return curd.Ne(fd.column, v), nil
+	case Gt:
+		return curd.Gt(fd.column, v), nil
+	case Gte:
+		return curd.Gte(fd.column, v), nil
+	case Lt:
+		return curd.Lt(fd.column, v), nil
+	case Lte:
+		return curd.Lte(fd.column, v), nil
+	default:
+		return curd.Eq(fd.column, v), nil
+	}
+}
+
+// orders parses the sort parameter, e.g. "-created_date,name".
+func (f *Filter[T]) orders(sort string) ([]curd.Order, Errors) {
+	if sort == "" {
+		return f.defaultOrder, nil
+	}
+	var orders []curd.Order
+	var errs Errors
+	for _, term := range strings.Split(sort, ",") {
+		col, desc := strings.CutPrefix(term, "-")
+		if !f.sortable[col] {
+			errs = append(errs, &Error{Param: SortParam, Value: sort, Code: NotSortable, Message: fmt.Sprintf("cannot sort by %q", col)})
+			continue
+		}
+		if desc {
+			orders = append(orders, curd.Desc(col))
+		} else {
+			orders = append(orders, curd.Asc(col))
+		}
+	}
+	return orders, errs
+}
+
+// positiveInt reads the integer parameter name, returning def when absent.
+// A non-zero limit is the largest value accepted.
+func positiveInt(q url.Values, name string, def, limit int) (int, *Error) {
+	raw := q.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || (limit > 0 && n > limit) {
+		msg := "expected a positive integer"
+		if limit > 0 {
+			msg = fmt.Sprintf("expected an integer between 1 and %d", limit)
+		}
+		return def, &Error{Param: name, Value: raw, Code: InvalidPage, Message: msg}
+	}
+	return n, nil
+}
+
+// convert parses s as a value of the field type t. Types without a textual
+// form here (strings, JSON columns, custom types) receive s unchanged.
+func convert(s string, t reflect.Type) (any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeFor[time.Time]() {
+		for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+			if ts, err := time.Parse(layout, s); err == nil {
+				return ts, nil
+			}
+		}
+		return nil, fmt.Errorf("expected an RFC 3339 time or a YYYY-MM-DD date")
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		return n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("expected a non-negative integer")
+		}
+		return n, nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return n, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false")
+		}
+		return b, nil
+	}
+	return s, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
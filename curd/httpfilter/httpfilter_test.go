@@ -0,0 +1,254 @@
+package httpfilter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+// ============================================
+// Mocks
+// ============================================
+
+type mockDialect struct{}
+
+func (mockDialect) Placeholder(n int) string      { return fmt.Sprintf("$%d", n) }
+func (mockDialect) QuoteIdent(name string) string { return name }
+func (mockDialect) SupportsReturning() bool       { return true }
+func (mockDialect) LimitOffset(limit, offset string) string {
+	var clause string
+	if limit != "" {
+		clause += " LIMIT " + limit
+	}
+	if offset != "" {
+		clause += " OFFSET " + offset
+	}
+	return clause
+}
+func (mockDialect) OnConflict(conflictCols, updateCols []string) string { return "" }
+
+type emptyRows struct{}
+
+func (emptyRows) Close()            {}
+func (emptyRows) Err() error        { return nil }
+func (emptyRows) Next() bool        { return false }
+func (emptyRows) Scan(...any) error { return nil }
+
+// recordingQuerier records the last query.
+type recordingQuerier struct {
+	sql  string
+	args []any
+}
+
+func (q *recordingQuerier) Query(_ context.Context, sql string, args ...any) (curd.Rows, error) {
+	q.sql, q.args = sql, args
+	return emptyRows{}, nil
+}
+
+func (q *recordingQuerier) QueryRow(context.Context, string, ...any) curd.Row { return nil }
+
+func (q *recordingQuerier) Exec(context.Context, string, ...any) (curd.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+type user struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	Age         int        `json:"age"`
+	Score       float64    `json:"score"`
+	Active      bool       `json:"active"`
+	CreatedDate time.Time  `json:"created_date"`
+	DeletedDate *time.Time `json:"deleted_date"`
+}
+
+func (user) TableName() string { return "users" }
+
+var userFilter = New[user](
+	Field("status", Eq, In, NotIn),
+	Field("age", Gte, Lte, Gt, Lt),
+	Field("name", ILike, Like),
+	Field("score", Gt),
+	Field("active"),
+	Field("created_date", Gte),
+	Field("deleted_date", IsNull),
+	Sortable("created_date", "name", "id"),
+	DefaultOrder(curd.Asc("id")),
+)
+
+// render runs opts through Curd.Find and returns the SQL and arguments.
+func render(t *testing.T, opts []curd.FindOption) (string, []any) {
+	t.Helper()
+	q := &recordingQuerier{}
+	c := curd.New[user](q, nil, mockDialect{})
+	if _, err := c.Find(context.Background(), opts...); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	return q.sql, q.args
+}
+
+func parseQuery(t *testing.T, raw string) url.Values {
+	t.Helper()
+	q, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// ============================================
+// Parse Tests
+// ============================================
+
+func TestParse(t *testing.T) {
+	q := parseQuery(t, "status=eq:active&age=gte:30&name=ilike:foo&sort=-created_date&page=2&size=50")
+	opts, err := userFilter.Parse(q)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sql, args := render(t, opts)
+	want := "SELECT id,name,status,age,score,active,created_date,deleted_date FROM users" +
+		" WHERE (age >= $1 AND name ILIKE $2 AND status = $3) AND deleted_date IS NULL" +
+		" ORDER BY created_date DESC LIMIT $4 OFFSET $5"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	wantArgs := []any{int64(30), "%foo%", "active", 50, 50}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestParseRepeatedSort(t *testing.T) {
+	opts, err := userFilter.Parse(parseQuery(t, "sort=-created_date&sort=name"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sql, _ := render(t, opts)
+	if !strings.Contains(sql, " ORDER BY created_date DESC, name ASC ") {
+		t.Errorf("expected both sort params applied: %s", sql)
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	opts, err := userFilter.Parse(url.Values{}, curd.Eq("tenant_id", 7))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	sql, args := render(t, opts)
+	want := "SELECT id,name,status,age,score,active,created_date,deleted_date FROM users" +
+		" WHERE (tenant_id = $1) AND deleted_date IS NULL ORDER BY id ASC LIMIT $2"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{7, DefaultPageSize}) {
+		t.Errorf("unexpected args: %#v", args)
+	}
+}
+
+func TestParseOperators(t *testing.T) {
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query string
+		where string
+		args  []any
+	}{
+		{"status=active", "status = $1", []any{"active"}},
+		{"status=in:a,b", "status IN ($1, $2)", []any{"a", "b"}},
+		{"status=nin:a", "status NOT IN ($1)", []any{"a"}},
+		{"age=gte:18&age=lt:65", "age >= $1 AND age < $2", []any{int64(18), int64(65)}},
+		{"name=like:50%25_off", `name LIKE $1`, []any{`%50\%\_off%`}},
+		{"score=gt:1.5", "score > $1", []any{1.5}},
+		{"active=true", "active = $1", []any{true}},
+		{"created_date=gte:2024-01-02", "created_date >= $1", []any{created}},
+		{"deleted_date=null:false", "deleted_date IS NOT NULL", nil},
+	}
+	for _, tt := range tests {
+		opts, err := userFilter.Parse(parseQuery(t, tt.query))
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		sql, args := render(t, opts)
+		want := "FROM users WHERE (" + tt.where + ") AND"
+		if !strings.Contains(sql, want) {
+			t.Errorf("%s: SQL %q does not contain %q", tt.query, sql, want)
+		}
+		if n := len(tt.args); n > 0 && !reflect.DeepEqual(args[:n], tt.args) {
+			t.Errorf("%s: args = %#v, want prefix %#v", tt.query, args, tt.args)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		param string
+		code  Code
+	}{
+		{"password=eq:x", "password", UnknownField},
+		{"status=gt:a", "status", OperatorNotAllowed},
+		{"age=eq:30", "age", OperatorNotAllowed},
+		{"age=gte:thirty", "age", InvalidValue},
+		{"created_date=gte:yesterday", "created_date", InvalidValue},
+		{"deleted_date=null:maybe", "deleted_date", InvalidValue},
+		{"sort=-age", SortParam, NotSortable},
+		{"page=0", PageParam, InvalidPage},
+		{"page=9223372036854775807", PageParam, InvalidPage},
+		{"size=1000", SizeParam, InvalidPage},
+	}
+	for _, tt := range tests {
+		_, err := userFilter.Parse(parseQuery(t, tt.query))
+		var fe *Error
+		if !errors.As(err, &fe) {
+			t.Errorf("%s: expected *Error, got %v", tt.query, err)
+			continue
+		}
+		if fe.Param != tt.param || fe.Code != tt.code {
+			t.Errorf("%s: got param %q code %q, want %q %q", tt.query, fe.Param, fe.Code, tt.param, tt.code)
+		}
+	}
+}
+
+func TestParseCollectsAllErrors(t *testing.T) {
+	_, err := userFilter.Parse(parseQuery(t, "age=gte:x&bogus=1&sort=secret"))
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("expected 3 errors, got %d: %v", len(errs), errs)
+	}
+	b, err := json.Marshal(errs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"param":"age","value":"gte:x","code":"invalid_value","message":"expected an integer"}`
+	if string(b) != want {
+		t.Errorf("JSON = %s, want %s", b, want)
+	}
+}
+
+func TestParseIgnoreUnknown(t *testing.T) {
+	f := New[user](Field("status"), IgnoreUnknown())
+	if _, err := f.Parse(parseQuery(t, "status=a&q=search")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewPanicsOnUnknownColumn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	New[user](Field("password"))
+}
@@ -0,0 +1,88 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// Iter streams the rows selected by opts one at a time instead of loading
+// them into a slice, keeping memory constant for large result sets. It
+// accepts the same options as Find, except WithPreload: relations are
+// loaded in batches over a whole result slice, so Iter rejects it.
+//
+// The query runs when iteration starts. Rows are closed when the loop ends,
+// including on break. A query, scan or context error is yielded once as the
+// final element.
+//
+// Usage:
+//
+//	for row, err := range c.Iter(ctx, curd.WithWhere(curd.Eq("status", "active"))) {
+//	    if err != nil { return err }
+//	    process(row)
+//	}
+func (c *Curd[T]) Iter(ctx context.Context, opts ...FindOption) iter.Seq2[T, error] {
+	cfg := resolveFindConfig(opts)
+	return func(yield func(T, error) bool) {
+		if len(cfg.preload) > 0 {
+			var zero T
+			yield(zero, fmt.Errorf("iter %s: WithPreload is not supported by Iter", tableName[T]()))
+			return
+		}
+		query, args, err := c.buildSelect(ctx, cfg)
+		if err != nil {
+			var zero T
+			yield(zero, fmt.Errorf("iter %s: %w", tableName[T](), err))
+			return
+		}
+		defer c.logSQL(ctx, query, args...)()
+		rows, err := c.db(ctx).Query(ctx, query, args...)
+		if err != nil {
+			var zero T
+			yield(zero, fmt.Errorf("iter %s: %w", tableName[T](), err))
+			return
+		}
+		yieldRows(ctx, rows, c.fm, c.decoder(), yield)
+	}
+}
+
+// QueryIter executes a raw SQL query and streams the results into T one row
+// at a time. Column mapping follows QueryRaw. See Curd.Iter for iteration
+// semantics.
+func QueryIter[T any](ctx context.Context, q Querier, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer logSQLGlobal(ctx, query, args...)()
+		rows, err := translating(q, queryTranslator(q)).Query(ctx, query, args...)
+		if err != nil {
+			var zero T
+			yield(zero, fmt.Errorf("query iter: %w", err))
+			return
+		}
+		yieldRows(ctx, rows, rawFieldMapper{}, decoderFromContext(ctx), yield)
+	}
+}
+
+// yieldRows scans rows one at a time into T and passes them to yield,
+// closing rows when done. It stops early when yield returns false or ctx
+// is cancelled.
+func yieldRows[T any](ctx context.Context, rows Rows, fm FieldMapper, d FieldDecoder, yield func(T, error) bool) {
+	defer rows.Close()
+	var zero T
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			yield(zero, err)
+			return
+		}
+		row, err := scanRowWithMapper[T](ctx, rows, fm, d)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if !yield(row, nil) {
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		yield(zero, err)
+	}
+}
@@ -0,0 +1,198 @@
+package curd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrInvalidCursor is returned by FindAfter when the cursor cannot be
+// decoded or does not match the sort key.
+var ErrInvalidCursor = errors.New("curd: invalid cursor")
+
+// defaultCursorPageSize is used by FindAfter when no WithLimit is given.
+const defaultCursorPageSize = 100
+
+// CursorPage holds a page of keyset-paginated results. NextCursor is empty
+// when there are no further rows.
+type CursorPage[T any] struct {
+	List       []T
+	NextCursor string
+}
+
+// FindAfter returns the page of rows that follows cursor, using keyset
+// pagination instead of OFFSET. Pass an empty cursor for the first page and
+// the returned NextCursor for each following page.
+//
+// The sort key comes from WithOrder (default primary key ASC) and must be
+// unique; append the primary key as a tie-breaker when sorting on
+// non-unique columns. Sort columns must be non-null. WithOffset is
+// ignored; WithLimit sets the page size (default 100).
+//
+// Usage:
+//
+//	page, err := c.FindAfter(ctx, req.Cursor,
+//	    curd.WithWhere(curd.Eq("status", "active")),
+//	    curd.WithOrder(curd.Desc("created_date"), curd.Desc("id")),
+//	    curd.WithLimit(50),
+//	)
+//	// respond with page.List and page.NextCursor
+func (c *Curd[T]) FindAfter(ctx context.Context, cursor string, opts ...FindOption) (*CursorPage[T], error) {
+	cfg := resolveFindConfig(opts)
+	orders := cfg.orders
+	if len(orders) == 0 {
+		orders = c.keyOrders()
+	}
+	orders, err := c.resolveOrders(orders)
+	if err != nil {
+		return nil, fmt.Errorf("findAfter %s: %w", tableName[T](), err)
+	}
+	limit := cfg.limit
+	if limit <= 0 {
+		limit = defaultCursorPageSize
+	}
+
+	var after []any
+	if cursor != "" {
+		if after, err = decodeCursor(cursor, len(orders)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	pageCfg := *cfg
+	pageCfg.limit = limit + 1
+	list, err := c.keysetPage(ctx, &pageCfg, orders, after)
+	if err != nil {
+		return nil, err
+	}
+	page := &CursorPage[T]{List: list}
+	if len(list) > limit {
+		page.List = list[:limit]
+		values, err := c.keysetValues(page.List[limit-1], orders)
+		if err != nil {
+			return nil, err
+		}
+		if page.NextCursor, err = encodeCursor(values); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// keysetPage fetches cfg.limit rows ordered by orders that sort strictly
+// after the key values in after (nil for the first page).
+func (c *Curd[T]) keysetPage(ctx context.Context, cfg *findConfig, orders []Order, after []any) ([]T, error) {
+	pageCfg := *cfg
+	pageCfg.orders = orders
+	pageCfg.orderBy = ""
+	pageCfg.offset = 0
+	if after != nil {
+		pageCfg.where = c.keysetPredicate(orders, after)
+		if cfg.where != nil {
+			pageCfg.where = And(cfg.where, pageCfg.where)
+		}
+	}
+	return c.find(ctx, &pageCfg)
+}
+
+// keysetPredicate renders the row-value comparison "sort key > after" for
+// mixed sort directions, e.g. for (a ASC, b DESC):
+// (a > $1) OR (a = $1 AND b < $2).
+func (c *Curd[T]) keysetPredicate(orders []Order, after []any) Predicate {
+	branches := make([]Predicate, len(orders))
+	for i, o := range orders {
+		conds := make([]Predicate, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, Eq(c.quote(orders[j].Column), after[j]))
+		}
+		if o.Desc {
+			conds = append(conds, Lt(c.quote(o.Column), after[i]))
+		} else {
+			conds = append(conds, Gt(c.quote(o.Column), after[i]))
+		}
+		branches[i] = And(conds...)
+	}
+	return Or(branches...)
+}
+
+// keysetValues reads the sort key values from row.
+func (c *Curd[T]) keysetValues(row T, orders []Order) ([]any, error) {
+	v := reflect.ValueOf(row)
+	values := make([]any, len(orders))
+	for i, o := range orders {
+		f, ok := fieldByColumn(v, c.fm, o.Column)
+		if !ok {
+			return nil, fmt.Errorf("keyset %s: no field for sort column %q", tableName[T](), o.Column)
+		}
+		values[i] = f.Interface()
+	}
+	return values, nil
+}
+
+// fieldByColumn returns the field of struct v mapped to column. A table
+// qualifier such as "t." is ignored.
+func fieldByColumn(v reflect.Value, fm FieldMapper, column string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	if idx := strings.LastIndexByte(column, '.'); idx >= 0 {
+		column = column[idx+1:]
+	}
+	for _, f := range flatFields(v.Type()) {
+		if f.columnName(fm) == column {
+			return readField(v, f.Index, f.Type), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// encodeCursor serializes key values as URL-safe base64 JSON.
+func encodeCursor(values []any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor parses a cursor produced by encodeCursor. Integral JSON
+// numbers are returned as int64 so they bind cleanly to integer columns.
+func decodeCursor(cursor string, n int) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var values []any
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if len(values) != n {
+		return nil, fmt.Errorf("%w: expected %d key values, got %d", ErrInvalidCursor, n, len(values))
+	}
+	for i, v := range values {
+		num, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if iv, err := num.Int64(); err == nil {
+			values[i] = iv
+		} else if fv, err := num.Float64(); err == nil {
+			values[i] = fv
+		}
+	}
+	return values, nil
+}
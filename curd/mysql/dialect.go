@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"strings"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+// maxLimit is the documented MySQL idiom for "no limit", required because
+// MySQL does not accept OFFSET without LIMIT.
+const maxLimit = "18446744073709551615"
+
+// Dialect implements curd.Dialect for MySQL (?, ?, ...).
+type Dialect struct{}
+
+func (Dialect) Placeholder(int) string {
+	return "?"
+}
+
+// QuoteIdent quotes identifiers with backticks (`users`.`id`).
+func (Dialect) QuoteIdent(name string) string {
+	return curd.QuoteIdentWith(name, '`')
+}
+
+// SupportsReturning reports false: generated ids are read via
+// curd.LastInsertIDResult instead.
+func (Dialect) SupportsReturning() bool { return false }
+
+func (Dialect) LimitOffset(limit, offset string) string {
+	if limit == "" && offset == "" {
+		return ""
+	}
+	if limit == "" {
+		limit = maxLimit
+	}
+	clause := " LIMIT " + limit
+	if offset != "" {
+		clause += " OFFSET " + offset
+	}
+	return clause
+}
+
+// OnConflict renders ON DUPLICATE KEY UPDATE col = VALUES(col). MySQL picks
+// the conflicting key itself, so conflictCols are only used for the
+// "do nothing" form, which assigns the first conflict column to itself.
+// Without conflictCols that form is "", and curd passes the primary key.
+func (d Dialect) OnConflict(conflictCols, updateCols []string) string {
+	if len(updateCols) == 0 {
+		if len(conflictCols) == 0 {
+			return ""
+		}
+		q := d.QuoteIdent(conflictCols[0])
+		return " ON DUPLICATE KEY UPDATE " + q + " = " + q
+	}
+	sets := make([]string, len(updateCols))
+	for i, c := range updateCols {
+		q := d.QuoteIdent(c)
+		sets[i] = q + " = VALUES(" + q + ")"
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ",")
+}
@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+func TestDialectPlaceholder(t *testing.T) {
+	d := Dialect{}
+	for _, n := range []int{1, 2, 10} {
+		if got := d.Placeholder(n); got != "?" {
+			t.Errorf("Placeholder(%d) = %q, want %q", n, got, "?")
+		}
+	}
+}
+
+func TestDialectQuoteIdent(t *testing.T) {
+	d := Dialect{}
+
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"users", "`users`"},
+		{"app.users", "`app`.`users`"},
+		{"count(*)", "count(*)"},
+		{"users AS u", "users AS u"},
+	}
+
+	for _, tt := range tests {
+		if got := d.QuoteIdent(tt.in); got != tt.expect {
+			t.Errorf("QuoteIdent(%q) = %q, want %q", tt.in, got, tt.expect)
+		}
+	}
+}
+
+func TestDialectLimitOffset(t *testing.T) {
+	d := Dialect{}
+
+	tests := []struct {
+		limit, offset string
+		expect        string
+	}{
+		{"", "", ""},
+		{"?", "", " LIMIT ?"},
+		{"?", "?", " LIMIT ? OFFSET ?"},
+		{"", "?", " LIMIT 18446744073709551615 OFFSET ?"},
+	}
+
+	for _, tt := range tests {
+		if got := d.LimitOffset(tt.limit, tt.offset); got != tt.expect {
+			t.Errorf("LimitOffset(%q, %q) = %q, want %q", tt.limit, tt.offset, got, tt.expect)
+		}
+	}
+}
+
+func TestDialectOnConflict(t *testing.T) {
+	d := Dialect{}
+
+	got := d.OnConflict([]string{"email"}, []string{"name", "age"})
+	want := " ON DUPLICATE KEY UPDATE `name` = VALUES(`name`),`age` = VALUES(`age`)"
+	if got != want {
+		t.Errorf("OnConflict update = %q, want %q", got, want)
+	}
+
+	got = d.OnConflict([]string{"email"}, nil)
+	want = " ON DUPLICATE KEY UPDATE `email` = `email`"
+	if got != want {
+		t.Errorf("OnConflict nothing = %q, want %q", got, want)
+	}
+
+	if got = d.OnConflict(nil, nil); got != "" {
+		t.Errorf("OnConflict nothing without columns = %q, want empty", got)
+	}
+}
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (user) TableName() string { return "users" }
+
+// execRecorder is a curd.Querier that records the last statement run.
+type execRecorder struct{ sql string }
+
+func (r *execRecorder) Query(context.Context, string, ...any) (curd.Rows, error) { return nil, nil }
+func (r *execRecorder) QueryRow(context.Context, string, ...any) curd.Row        { return nil }
+func (r *execRecorder) Exec(_ context.Context, sql string, _ ...any) (curd.Result, error) {
+	r.sql = sql
+	return affected(0), nil
+}
+
+type affected int64
+
+func (a affected) RowsAffected() int64 { return int64(a) }
+
+func TestInsertIgnoreWithoutConflictColumns(t *testing.T) {
+	rec := &execRecorder{}
+	users := curd.New[user](rec, nil, Dialect{})
+
+	inserted, err := users.InsertIgnore(context.Background(), &user{Name: "a"}, nil)
+	if err != nil || inserted {
+		t.Fatalf("InsertIgnore = %v, %v; want a skipped row", inserted, err)
+	}
+	want := "INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `id` = `id`"
+	if rec.sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", rec.sql, want)
+	}
+}
+
+func TestDialectSupportsReturning(t *testing.T) {
+	if (Dialect{}).SupportsReturning() {
+		t.Error("MySQL dialect should not support RETURNING")
+	}
+}
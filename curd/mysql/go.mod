@@ -0,0 +1,7 @@
+module github.com/gobkc/do/curd/mysql
+
+go 1.25.0
+
+require github.com/gobkc/do/curd v0.0.0-20260624183304-3de19f2da4dd
+
+replace github.com/gobkc/do/curd => ..
@@ -0,0 +1,252 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pkField describes one primary-key column of an entity type.
+type pkField struct {
+	index  []int // field index path within the struct; nil when T has no such field
+	name   string
+	column string
+	typ    reflect.Type
+}
+
+// defaultPK is used when an entity declares no primary key and has no ID
+// field: the column is assumed to be "id".
+var defaultPK = []pkField{{name: "ID", column: "id"}}
+
+// Key is an explicit primary-key value list, in primary-key field order.
+// Build it with PK.
+type Key []any
+
+// PK returns a Key for composite primary keys, for use with the *ByID
+// methods:
+//
+//	c.UpdateByID(ctx, curd.PK(tenantID, code), updates)
+func PK(values ...any) Key { return Key(values) }
+
+// primaryKeyFields returns the primary-key fields of struct type t.
+//
+// Fields tagged curd:"pk" (or gorm:"primaryKey") form the key, in
+// declaration order; several tagged fields make a composite key. Without
+// tags, a field named ID is the key. Any scalar type is allowed.
+func primaryKeyFields(t reflect.Type, fm FieldMapper) []pkField {
+	var tagged []pkField
+	var byName []pkField
+	for _, f := range flatFields(t) {
+		col := f.columnName(fm)
+		if col == "" {
+			continue
+		}
+		pk := pkField{index: f.Index, name: f.Name, column: col, typ: f.Type}
+		if hasTagOption(f.StructField, "curd", "pk") || hasTagOption(f.StructField, "gorm", "primarykey") {
+			tagged = append(tagged, pk)
+		} else if f.Name == "ID" {
+			byName = append(byName, pk)
+		}
+	}
+	if len(tagged) > 0 {
+		return tagged
+	}
+	return byName
+}
+
+// hasTagOption reports whether the ;-separated struct tag key contains opt
+// (case-insensitive).
+func hasTagOption(f reflect.StructField, key, opt string) bool {
+	tag := f.Tag.Get(key)
+	if tag == "" {
+		return false
+	}
+	for _, part := range strings.Split(tag, ";") {
+		if strings.EqualFold(strings.TrimSpace(part), opt) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPKColumn reports whether col is one of the primary-key columns in pk.
+func isPKColumn(pk []pkField, col string) bool {
+	for _, p := range pk {
+		if p.column == col {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFields returns T's primary key, falling back to the "id" column.
+func (c *Curd[T]) keyFields() []pkField {
+	if len(c.pk) > 0 {
+		return c.pk
+	}
+	return defaultPK
+}
+
+// keyOrders returns an ascending Order on every primary-key column, the
+// default sort key for keyset pagination.
+func (c *Curd[T]) keyOrders() []Order {
+	pk := c.keyFields()
+	orders := make([]Order, len(pk))
+	for i, p := range pk {
+		orders[i] = Asc(p.column)
+	}
+	return orders
+}
+
+// generatedPK returns the single primary-key field whose value the database
+// generates on insert, or nil for composite keys and key-less entities.
+func (c *Curd[T]) generatedPK() *pkField {
+	if len(c.pk) != 1 {
+		return nil
+	}
+	return &c.pk[0]
+}
+
+// pkPredicate builds the WHERE predicate identifying one row by primary key.
+//
+// id may be a single scalar value, a Key built with PK, or a struct (or
+// pointer to struct, e.g. *T) carrying fields named like the key fields.
+// Several values may also be passed directly.
+func (c *Curd[T]) pkPredicate(id ...any) (Predicate, error) {
+	values, err := c.keyValues(id...)
+	if err != nil {
+		return nil, err
+	}
+	return c.keyPredicate(values), nil
+}
+
+// keyValues normalizes the id forms accepted by pkPredicate into one value
+// per primary-key field.
+func (c *Curd[T]) keyValues(id ...any) ([]any, error) {
+	pk := c.keyFields()
+	values := id
+	if len(id) == 1 {
+		switch k := id[0].(type) {
+		case Key:
+			values = k
+		default:
+			if vals, ok := keyFromStruct(k, pk); ok {
+				values = vals
+			}
+		}
+	}
+	if len(values) != len(pk) {
+		return nil, fmt.Errorf("%s: expected %d primary key value(s), got %d", tableName[T](), len(pk), len(values))
+	}
+	return values, nil
+}
+
+// keyPredicate matches the primary-key columns against values.
+func (c *Curd[T]) keyPredicate(values []any) Predicate {
+	pk := c.keyFields()
+	if len(pk) == 1 {
+		return Eq(c.quote(pk[0].column), values[0])
+	}
+	conds := make([]Predicate, len(pk))
+	for i, p := range pk {
+		conds[i] = Eq(c.quote(p.column), values[i])
+	}
+	return And(conds...)
+}
+
+// keyFromStruct extracts the primary-key values from a struct that has a
+// field for every key field. It reports false for scalars and for structs
+// that are not key carriers (e.g. sql.NullString, time.Time).
+func keyFromStruct(v any, pk []pkField) ([]any, bool) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	values := make([]any, len(pk))
+	for i, p := range pk {
+		f, ok := fieldByName(rv.Type(), p.name)
+		if !ok {
+			return nil, false
+		}
+		values[i] = readField(rv, f.Index, f.Type).Interface()
+	}
+	return values, true
+}
+
+// pkValues reads the primary-key values from row value v. ok is false when
+// the entity has no primary-key field.
+func pkValues(v reflect.Value, pk []pkField) (values []any, ok bool) {
+	v = derefValue(v)
+	if !v.IsValid() || len(pk) == 0 {
+		return nil, false
+	}
+	values = make([]any, len(pk))
+	for i, p := range pk {
+		if p.index == nil {
+			return nil, false
+		}
+		values[i] = readField(v, p.index, p.typ).Interface()
+	}
+	return values, true
+}
+
+// scanGeneratedKey runs an INSERT ... RETURNING <pk> query and scans the key
+// straight into the primary-key field of v.
+func (c *Curd[T]) scanGeneratedKey(ctx context.Context, v reflect.Value, pk *pkField, query string, args []any) error {
+	ctx = UsePrimary(ctx) // a write, though sent with QueryRow
+	query += " RETURNING " + c.quote(pk.column)
+	defer c.logSQL(ctx, query, args...)()
+	f := settableField(derefValue(v), pk.index)
+	return c.db(ctx).QueryRow(ctx, query, args...).Scan(f.Addr().Interface())
+}
+
+// setLastInsertID writes a driver-reported AUTO_INCREMENT id into the
+// primary-key field of v when that field is an unset integer.
+func setLastInsertID(v reflect.Value, pk *pkField, res Result) error {
+	lr, ok := res.(LastInsertIDResult)
+	if !ok {
+		return nil
+	}
+	f := settableField(derefValue(v), pk.index)
+	if !f.IsValid() || !f.IsZero() || !f.CanSet() {
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return nil
+	}
+	id, err := lr.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("last insert id: %w", err)
+	}
+	if id <= 0 {
+		return nil
+	}
+	if f.CanInt() {
+		f.SetInt(id)
+	} else {
+		f.SetUint(uint64(id))
+	}
+	return nil
+}
+
+// derefValue follows pointers until it reaches a non-pointer value, returning
+// the zero Value for nil pointers.
+func derefValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
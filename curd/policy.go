@@ -0,0 +1,295 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SoftDeleteKind selects how a soft-delete column marks deleted rows.
+type SoftDeleteKind int
+
+const (
+	// SoftDeleteTimestamp stores the deletion time; NULL means live.
+	SoftDeleteTimestamp SoftDeleteKind = iota
+	// SoftDeleteUnix stores the deletion time as integer unix seconds;
+	// 0 means live. The column should be NOT NULL DEFAULT 0.
+	SoftDeleteUnix
+	// SoftDeleteFlag stores a boolean; false means live. The column should
+	// be NOT NULL DEFAULT false.
+	SoftDeleteFlag
+)
+
+// SoftDeletePolicy describes how rows of an entity are soft-deleted.
+// The policy is active for an entity type only if it has Field; otherwise
+// reads are unfiltered and DeleteWhere hard-deletes.
+type SoftDeletePolicy struct {
+	Field  string         // Go field name, e.g. "DeletedDate"
+	Column string         // column name; empty uses Field's mapped column
+	Kind   SoftDeleteKind // column representation
+}
+
+// DefaultSoftDelete is the policy used unless WithSoftDelete is given:
+// a nullable deleted_date timestamp on the DeletedDate field.
+var DefaultSoftDelete = SoftDeletePolicy{Field: "DeletedDate", Column: "deleted_date"}
+
+// TimestampPolicy names the fields stamped with the current time on insert
+// (CreatedField and ChangedField) and on update (ChangedField). time.Time
+// and *time.Time fields receive time.Now(); integer fields receive unix
+// seconds. An empty name disables that timestamp.
+type TimestampPolicy struct {
+	CreatedField string
+	ChangedField string
+}
+
+// DefaultTimestamps is the policy used unless WithTimestamps is given.
+var DefaultTimestamps = TimestampPolicy{CreatedField: "CreatedDate", ChangedField: "ChangedDate"}
+
+// WithSoftDelete sets the soft-delete policy. Pass SoftDeletePolicy{} to
+// disable soft deletes entirely.
+func WithSoftDelete(p SoftDeletePolicy) CurdOption {
+	return func(c *curdConfig) { c.softDelete = p }
+}
+
+// WithTimestamps sets the automatic timestamp policy.
+func WithTimestamps(p TimestampPolicy) CurdOption {
+	return func(c *curdConfig) { c.timestamps = p }
+}
+
+// softDeleteColumn is a SoftDeletePolicy resolved against an entity type.
+type softDeleteColumn struct {
+	column  string
+	kind    SoftDeleteKind
+	onField bool // T has the policy field, so reads are filtered
+}
+
+// resolveSoftDelete resolves p against struct type t. It returns nil when
+// the policy names no column and t lacks the field.
+func resolveSoftDelete(t reflect.Type, fm FieldMapper, p SoftDeletePolicy) *softDeleteColumn {
+	if p.Field == "" && p.Column == "" {
+		return nil
+	}
+	sd := &softDeleteColumn{column: p.Column, kind: p.Kind}
+	if f, ok := fieldByName(t, p.Field); ok {
+		sd.onField = true
+		if sd.column == "" {
+			sd.column = f.columnName(fm)
+		}
+	}
+	if sd.column == "" {
+		return nil
+	}
+	return sd
+}
+
+// liveSQL renders the condition matching rows that are not soft-deleted.
+func (sd *softDeleteColumn) liveSQL(col string) string {
+	switch sd.kind {
+	case SoftDeleteUnix:
+		return col + " = 0"
+	case SoftDeleteFlag:
+		return col + " = FALSE"
+	default:
+		return col + " IS NULL"
+	}
+}
+
+// trashedSQL renders the condition matching soft-deleted rows.
+func (sd *softDeleteColumn) trashedSQL(col string) string {
+	switch sd.kind {
+	case SoftDeleteUnix:
+		return col + " <> 0"
+	case SoftDeleteFlag:
+		return col + " = TRUE"
+	default:
+		return col + " IS NOT NULL"
+	}
+}
+
+// deletedValue returns the value written to mark a row deleted at now.
+func (sd *softDeleteColumn) deletedValue(now time.Time) any {
+	switch sd.kind {
+	case SoftDeleteUnix:
+		return now.Unix()
+	case SoftDeleteFlag:
+		return true
+	default:
+		return now
+	}
+}
+
+// restoredValue returns the value written to un-delete a row.
+func (sd *softDeleteColumn) restoredValue() any {
+	switch sd.kind {
+	case SoftDeleteUnix:
+		return int64(0)
+	case SoftDeleteFlag:
+		return false
+	default:
+		return nil
+	}
+}
+
+// trashedScope selects which rows a read sees with respect to soft deletes.
+type trashedScope int
+
+const (
+	scopeLive trashedScope = iota
+	scopeWithTrashed
+	scopeOnlyTrashed
+)
+
+// WithTrashed includes soft-deleted rows in the result.
+func WithTrashed() FindOption {
+	return func(c *findConfig) { c.trashed = scopeWithTrashed }
+}
+
+// OnlyTrashed restricts the result to soft-deleted rows.
+func OnlyTrashed() FindOption {
+	return func(c *findConfig) { c.trashed = scopeOnlyTrashed }
+}
+
+// softDeleteFilter renders the soft-delete condition for scope, or "" when
+// no filter applies.
+func (c *Curd[T]) softDeleteFilter(scope trashedScope) string {
+	sd := c.softDelete
+	if sd == nil || !sd.onField {
+		return ""
+	}
+	switch scope {
+	case scopeWithTrashed:
+		return ""
+	case scopeOnlyTrashed:
+		return sd.trashedSQL(c.quote(sd.column))
+	default:
+		return sd.liveSQL(c.quote(sd.column))
+	}
+}
+
+// changedColumn returns the column stamped on update and its current value,
+// or "" when T has no ChangedField.
+func (c *Curd[T]) changedColumn(now time.Time) (string, any) {
+	f, ok := fieldByName(reflect.TypeFor[T](), c.timestamps.ChangedField)
+	if !ok {
+		return "", nil
+	}
+	col := f.columnName(c.fm)
+	if col == "" {
+		return "", nil
+	}
+	val, ok := nowValue(f.Type, now)
+	if !ok {
+		return "", nil
+	}
+	return col, val
+}
+
+// createdColumn returns the column of T's CreatedField, or "".
+func (c *Curd[T]) createdColumn() string {
+	f, ok := fieldByName(reflect.TypeFor[T](), c.timestamps.CreatedField)
+	if !ok {
+		return ""
+	}
+	return f.columnName(c.fm)
+}
+
+// stampCreated sets the created and changed timestamps on row value v.
+func (c *Curd[T]) stampCreated(v reflect.Value) {
+	setNow(v, c.timestamps.CreatedField)
+	setNow(v, c.timestamps.ChangedField)
+}
+
+// stampChanged sets the changed timestamp on row value v.
+func (c *Curd[T]) stampChanged(v reflect.Value) {
+	setNow(v, c.timestamps.ChangedField)
+}
+
+// nowValue converts now to a value assignable to a timestamp field of type
+// t: time.Time, *time.Time or an integer holding unix seconds.
+func nowValue(t reflect.Type, now time.Time) (any, bool) {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return now, true
+	case reflect.TypeFor[*time.Time]():
+		return &now, true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(now.Unix()).Convert(t).Interface(), true
+	}
+	return nil, false
+}
+
+// --- Soft-delete operations ---
+
+// Restore un-deletes the soft-deleted rows matching the predicate.
+//
+// Usage:
+//
+//	err := c.Restore(ctx, curd.Eq("id", 42))
+func (c *Curd[T]) Restore(ctx context.Context, where Predicate) error {
+	tableName := tableName[T]()
+	sd := c.softDelete
+	if sd == nil {
+		return fmt.Errorf("restore %s: no soft-delete column", tableName)
+	}
+	if err := c.setSoftDeleted(ctx, where, sd.restoredValue(), sd.trashedSQL(c.quote(sd.column))); err != nil {
+		return fmt.Errorf("restore %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// ForceDelete hard-deletes the rows matching the predicate, including
+// soft-deleted ones, regardless of the soft-delete policy.
+func (c *Curd[T]) ForceDelete(ctx context.Context, where Predicate) error {
+	if err := beforeScopedDelete[T](ctx, WriteScope{Where: where}); err != nil {
+		return fmt.Errorf("force delete %s: %w", tableName[T](), err)
+	}
+	if err := c.forceDelete(ctx, where); err != nil {
+		return fmt.Errorf("force delete %s: %w", tableName[T](), err)
+	}
+	return nil
+}
+
+// forceDelete is ForceDelete without the BeforeScopedDelete hook. Like
+// setSoftDeleted it leaves wrapping the error to the caller.
+func (c *Curd[T]) forceDelete(ctx context.Context, where Predicate) error {
+	whereClause, args, err := buildPredicate(where, c.dialect)
+	if err != nil {
+		return err
+	}
+	whereSQL := ""
+	if whereClause != "" {
+		whereSQL = " WHERE " + whereClause
+	}
+	query := fmt.Sprintf("DELETE FROM %s%s", c.quote(tableName[T]()), whereSQL)
+	defer c.logSQL(ctx, query, args...)()
+	_, err = c.db(ctx).Exec(ctx, query, args...)
+	return err
+}
+
+// setSoftDeleted writes val to the soft-delete column of the rows matching
+// where and the optional scope condition, e.g. "deleted_date IS NULL".
+func (c *Curd[T]) setSoftDeleted(ctx context.Context, where Predicate, val any, scope string) error {
+	whereClause, whereArgs, err := buildPredicateAt(where, c.dialect, 2)
+	if err != nil {
+		return err
+	}
+	var conds []string
+	if whereClause != "" {
+		conds = append(conds, whereClause)
+	}
+	if scope != "" {
+		conds = append(conds, scope)
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = %s", c.quote(tableName[T]()), c.quote(c.softDelete.column), c.dialect.Placeholder(1))
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	args := append([]any{val}, whereArgs...)
+	defer c.logSQL(ctx, query, args...)()
+	_, err = c.db(ctx).Exec(ctx, query, args...)
+	return err
+}
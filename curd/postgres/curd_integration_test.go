@@ -12,6 +12,8 @@ import (
 	"time"
 
 	curd "github.com/gobkc/do/curd"
+
+	"github.com/jackc/pgx/v5"
 )
 
 const testDSN = ""
@@ -290,6 +292,75 @@ func TestIntegrationInsertWithXMLTransformer(t *testing.T) {
 	}
 }
 
+func TestIntegrationJSONBRoundTrip(t *testing.T) {
+	truncateTable(t)
+	c := curd.New[integrationItemWithJSONMap](testPool, nil, Dialect{}).
+		WithTransformer(curd.JSONBMarshaler("metadata")).
+		WithDecoder(curd.JSONBUnmarshaler("metadata"))
+
+	row := &integrationItemWithJSONMap{
+		Name:     "jsonb-roundtrip",
+		Metadata: map[string]any{"env": "prod", "tags": []any{"a", "b"}},
+	}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	found, err := c.FindByID(context.Background(), row.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Metadata["env"] != "prod" {
+		t.Errorf("expected env=prod, got %v", found.Metadata)
+	}
+}
+
+func TestIntegrationRawAnyAndJSONPath(t *testing.T) {
+	truncateTable(t)
+	ctx := context.Background()
+	c := curd.New[integrationItemWithJSONMap](testPool, nil, Dialect{}).
+		WithTransformer(curd.JSONBMarshaler("metadata"))
+
+	for i, env := range []string{"prod", "dev", "prod"} {
+		row := &integrationItemWithJSONMap{Name: fmt.Sprintf("Item-%d", i), Value: i, Metadata: map[string]any{"env": env, "qty": i * 10}}
+		if err := c.InsertOne(ctx, row); err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+	}
+
+	count, err := c.Count(ctx, curd.And(
+		curd.Raw("lower(name) = ANY(?)", []string{"item-0", "item-2"}),
+		curd.JSONPathMatch("metadata", `$.env == "prod"`),
+	))
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows, got %d", count)
+	}
+
+	count, err = c.Count(ctx, curd.And(
+		curd.Any("value", []int32{1, 2}),
+		curd.JSONPathExists("metadata", `$.qty ? (@ > 15)`),
+	))
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 row, got %d", count)
+	}
+}
+
+func TestIntegrationQueryRowRawNotFound(t *testing.T) {
+	truncateTable(t)
+
+	_, err := curd.QueryRowRaw[integrationItem](context.Background(), testPool,
+		"SELECT id, name FROM curd_test_items WHERE id = $1", -1)
+	if !errors.Is(err, curd.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 // ============================================
 // Query Tests
 // ============================================
@@ -379,8 +450,8 @@ func TestIntegrationFindOne(t *testing.T) {
 	}
 
 	_, err = c.FindOne(context.Background(), curd.Eq("name", "nonexistent"))
-	if err == nil {
-		t.Error("expected error for not found")
+	if !errors.Is(err, curd.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
 
@@ -979,6 +1050,49 @@ func TestIntegrationSoftDeleteFiltering(t *testing.T) {
 	}
 }
 
+func TestIntegrationTrashedRestoreForceDelete(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		c.InsertOne(ctx, &integrationItem{Name: fmt.Sprintf("tr-%d", i), Value: i})
+	}
+	if err := c.DeleteWhere(ctx, curd.Eq("name", "tr-1")); err != nil {
+		t.Fatalf("DeleteWhere: %v", err)
+	}
+
+	trashed, err := c.Find(ctx, curd.OnlyTrashed())
+	if err != nil {
+		t.Fatalf("Find OnlyTrashed: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].Name != "tr-1" {
+		t.Fatalf("expected tr-1 trashed, got %+v", trashed)
+	}
+	all, err := c.Find(ctx, curd.WithTrashed())
+	if err != nil {
+		t.Fatalf("Find WithTrashed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 rows with trashed, got %d", len(all))
+	}
+
+	if err := c.Restore(ctx, curd.Eq("name", "tr-1")); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if count, _ := c.Count(ctx, nil); count != 3 {
+		t.Errorf("expected 3 live rows after restore, got %d", count)
+	}
+
+	if err := c.ForceDelete(ctx, curd.Eq("name", "tr-2")); err != nil {
+		t.Fatalf("ForceDelete: %v", err)
+	}
+	all, _ = c.Find(ctx, curd.WithTrashed())
+	if len(all) != 2 {
+		t.Errorf("expected 2 rows after force delete, got %d", len(all))
+	}
+}
+
 // ============================================
 // Composite Transformers Test
 // ============================================
@@ -1037,6 +1151,377 @@ func TestIntegrationComposeTransformers(t *testing.T) {
 	}
 }
 
+// ============================================
+// Native Upsert Tests
+// ============================================
+
+func TestIntegrationUpsertOnConflict(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+
+	row := &integrationItem{Name: "first", Value: 1}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	dup := &integrationItem{ID: row.ID, Name: "second", Value: 2}
+	if err := c.UpsertOnConflict(context.Background(), dup, []string{"id"}, []string{"name", "value"}); err != nil {
+		t.Fatalf("UpsertOnConflict: %v", err)
+	}
+	if dup.ID != row.ID {
+		t.Errorf("expected existing ID %d, got %d", row.ID, dup.ID)
+	}
+
+	found, err := c.FindByID(context.Background(), row.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Name != "second" || found.Value != 2 {
+		t.Errorf("expected updated row, got %+v", found)
+	}
+
+	inserted, err := c.InsertIgnore(context.Background(), &integrationItem{ID: row.ID, Name: "third"}, []string{"id"})
+	if err != nil {
+		t.Fatalf("InsertIgnore: %v", err)
+	}
+	if inserted {
+		t.Error("expected conflicting InsertIgnore to be skipped")
+	}
+}
+
+func TestIntegrationUpsertBatchOnConflict(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+
+	row := &integrationItem{Name: "existing", Value: 1}
+	if err := c.InsertOne(context.Background(), row); err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	rows := []integrationItem{
+		{ID: row.ID, Name: "existing", Value: 10},
+		{ID: row.ID + 100, Name: "new", Value: 20},
+	}
+	if err := c.UpsertBatchOnConflict(context.Background(), rows, []string{"id"}, []string{"value"}); err != nil {
+		t.Fatalf("UpsertBatchOnConflict: %v", err)
+	}
+
+	count, err := c.Count(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows, got %d", count)
+	}
+	found, err := c.FindByID(context.Background(), row.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Value != 10 {
+		t.Errorf("expected value 10, got %d", found.Value)
+	}
+}
+
+// ============================================
+// Bulk Insert Tests
+// ============================================
+
+func TestIntegrationInsertBatchCopy(t *testing.T) {
+	truncateTable(t)
+	c := curd.New[integrationItem](testPool, nil, Dialect{}, curd.WithCopyThreshold(100))
+
+	rows := make([]integrationItem, 500)
+	for i := range rows {
+		rows[i] = integrationItem{Name: fmt.Sprintf("copy-%d", i), Value: i}
+	}
+	if err := c.InsertBatch(context.Background(), rows); err != nil {
+		t.Fatalf("InsertBatch via COPY: %v", err)
+	}
+
+	count, err := c.Count(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 500 {
+		t.Errorf("expected 500 rows, got %d", count)
+	}
+}
+
+func TestIntegrationInsertBatchChunked(t *testing.T) {
+	truncateTable(t)
+	c := curd.New[integrationItem](testPool, nil, Dialect{}, curd.WithCopyThreshold(0))
+
+	// 7 columns per row exceeds 65535 bind parameters after ~9362 rows.
+	rows := make([]integrationItem, 12000)
+	for i := range rows {
+		rows[i] = integrationItem{Name: "chunk", Value: i}
+	}
+	if err := c.InsertBatch(context.Background(), rows); err != nil {
+		t.Fatalf("InsertBatch chunked: %v", err)
+	}
+
+	count, err := c.Count(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 12000 {
+		t.Errorf("expected 12000 rows, got %d", count)
+	}
+}
+
+// ============================================
+// Keyset Pagination Tests
+// ============================================
+
+func TestIntegrationFindAfter(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+
+	for i := 0; i < 5; i++ {
+		c.InsertOne(context.Background(), &integrationItem{Name: fmt.Sprintf("k-%d", i), Value: i % 2})
+	}
+
+	var names []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("too many pages")
+		}
+		page, err := c.FindAfter(context.Background(), cursor,
+			curd.WithOrder(curd.Desc("value"), curd.Asc("id")),
+			curd.WithLimit(2),
+		)
+		if err != nil {
+			t.Fatalf("FindAfter: %v", err)
+		}
+		for _, r := range page.List {
+			names = append(names, r.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	want := []string{"k-1", "k-3", "k-0", "k-2", "k-4"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
+// ============================================
+// Aggregate Test
+// ============================================
+
+func TestIntegrationAggregate(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+	ctx := context.Background()
+
+	for i, name := range []string{"a", "a", "b", "b", "b", "c"} {
+		c.InsertOne(ctx, &integrationItem{Name: name, Value: i + 1})
+	}
+	// The soft-deleted "c" row must not form a group.
+	if err := c.DeleteWhere(ctx, curd.Eq("name", "c")); err != nil {
+		t.Fatalf("DeleteWhere: %v", err)
+	}
+
+	type nameTotal struct {
+		Name  string `json:"name"`
+		Total int64  `json:"total"`
+		Rows  int64  `json:"rows"`
+	}
+	got, err := curd.Aggregate[nameTotal](ctx, c,
+		curd.WithGroupBy("name"),
+		curd.WithAggregates(curd.Sum("value").As("total"), curd.Count("*").As("rows")),
+		curd.WithHaving(curd.Gt(curd.Count("*").Expr(), 1)),
+		curd.WithOrder(curd.Desc("total")),
+	)
+	if err != nil {
+		t.Fatalf("Aggregate: %v", err)
+	}
+	want := []nameTotal{{"b", 12, 3}, {"a", 3, 2}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("group %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+// ============================================
+// Projection Test
+// ============================================
+
+func TestIntegrationFindInto(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+	ctx := context.Background()
+
+	for i, name := range []string{"a", "b", "c"} {
+		c.InsertOne(ctx, &integrationItem{Name: name, Value: i + 1})
+	}
+
+	type itemView struct {
+		ID     int64  `json:"id"`
+		Label  string `json:"label"`
+		Parent string `json:"parent"`
+	}
+	page, err := curd.FindPaginatedInto[itemView](ctx, c,
+		curd.WithJoins(curd.JoinClause{Type: curd.LeftJoin, Table: "curd_test_items AS p", On: "p.value = curd_test_items.value - 1"}),
+		curd.WithColumns("curd_test_items.id", "curd_test_items.name AS label", "p.name AS parent"),
+		curd.WithWhere(curd.Gt("curd_test_items.value", 1)),
+		curd.WithOrder(curd.Desc("label")),
+		curd.WithLimit(1),
+	)
+	if err != nil {
+		t.Fatalf("FindPaginatedInto: %v", err)
+	}
+	if page.Total != 2 || len(page.List) != 1 {
+		t.Fatalf("expected 1 of 2 rows, got %d of %d", len(page.List), page.Total)
+	}
+	if got := page.List[0]; got.ID == 0 || got.Label != "c" || got.Parent != "b" {
+		t.Errorf("unexpected row: %+v", got)
+	}
+}
+
+// ============================================
+// Row Lock Test
+// ============================================
+
+func TestIntegrationFindWithLockSkipLocked(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+	ctx := context.Background()
+
+	for i := 1; i <= 4; i++ {
+		c.InsertOne(ctx, &integrationItem{Name: fmt.Sprintf("job-%d", i), Value: i})
+	}
+	if _, err := c.Find(ctx, curd.WithLock(curd.ForUpdate)); !errors.Is(err, curd.ErrLockWithoutTx) {
+		t.Fatalf("expected ErrLockWithoutTx, got %v", err)
+	}
+
+	claim := func(ctx context.Context, tx curd.Querier) ([]integrationItem, error) {
+		return c.WithQuerier(tx).Find(ctx,
+			curd.WithOrder(curd.Asc("value")),
+			curd.WithLimit(2),
+			curd.WithLock(curd.ForUpdate, curd.SkipLocked),
+		)
+	}
+	err := curd.WithTx(ctx, testPool, func(ctx context.Context, tx curd.Querier) error {
+		first, err := claim(ctx, tx)
+		if err != nil {
+			return err
+		}
+		// A concurrent transaction skips the rows locked by this one.
+		second, err := curd.WithTxResult(ctx, testPool, claim)
+		if err != nil {
+			return err
+		}
+		if len(first) != 2 || len(second) != 2 || first[0].Value != 1 || second[0].Value != 3 {
+			t.Errorf("unexpected claims: %+v then %+v", first, second)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+}
+
+// ============================================
+// Context Transaction Test
+// ============================================
+
+func TestIntegrationNestedWithTxSavepoint(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+
+	innerErr := errors.New("inner failed")
+	err := curd.WithTx(context.Background(), testPool, func(ctx context.Context, _ curd.Querier) error {
+		if err := c.InsertOne(ctx, &integrationItem{Name: "outer", Value: 1}); err != nil {
+			return err
+		}
+		err := curd.WithTx(ctx, testPool, func(ctx context.Context, _ curd.Querier) error {
+			if err := c.InsertOne(ctx, &integrationItem{Name: "inner", Value: 2}); err != nil {
+				return err
+			}
+			return innerErr
+		})
+		if !errors.Is(err, innerErr) {
+			return fmt.Errorf("expected inner error, got %v", err)
+		}
+		// The ambient transaction sees its own uncommitted row.
+		n, err := c.Count(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if n != 1 {
+			return fmt.Errorf("expected 1 row inside the transaction, got %d", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	items, err := c.Find(context.Background())
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "outer" {
+		t.Errorf("expected only the outer row committed, got %+v", items)
+	}
+}
+
+// ============================================
+// Transaction Retry Test
+// ============================================
+
+func TestIntegrationWithTxRetrySerializable(t *testing.T) {
+	truncateTable(t)
+	c := newCurd()
+	serializable := testPool.WithTxOptions(pgx.TxOptions{IsoLevel: pgx.Serializable})
+
+	var retries []error
+	attempts := 0
+	err := curd.WithTxRetry(context.Background(), serializable, curd.TxRetryOptions{
+		MaxAttempts: 3,
+		OnRetry: func(_ context.Context, _ int, err error, _ time.Duration) {
+			retries = append(retries, err)
+		},
+	}, func(ctx context.Context, _ curd.Querier) error {
+		attempts++
+		if _, err := c.Count(ctx, nil); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// A concurrent serializable transaction reads and writes the
+			// same rows, so one of the two must be aborted.
+			err := curd.WithTx(context.Background(), serializable, func(ctx context.Context, _ curd.Querier) error {
+				if _, err := c.Count(ctx, nil); err != nil {
+					return err
+				}
+				return c.InsertOne(ctx, &integrationItem{Name: "concurrent", Value: 1})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return c.InsertOne(ctx, &integrationItem{Name: "retried", Value: attempts})
+	})
+	if err != nil {
+		t.Fatalf("WithTxRetry: %v", err)
+	}
+	if attempts != 2 || len(retries) != 1 || !errors.Is(retries[0], curd.ErrSerializationFailure) {
+		t.Errorf("expected one retry after a serialization failure, got %d attempts, retries %v", attempts, retries)
+	}
+	if n, _ := c.Count(context.Background(), nil); n != 2 {
+		t.Errorf("expected 2 rows, got %d", n)
+	}
+}
+
 // ============================================
 // Helpers
 // ============================================
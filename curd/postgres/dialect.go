@@ -1,6 +1,11 @@
 package postgres
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+
+	curd "github.com/gobkc/do/curd"
+)
 
 // Dialect implements curd.Dialect for PostgreSQL ($1, $2, ...).
 type Dialect struct{}
@@ -8,3 +13,51 @@ type Dialect struct{}
 func (Dialect) Placeholder(n int) string {
 	return "$" + strconv.Itoa(n)
 }
+
+// QuoteIdent quotes identifiers with double quotes ("users"."id"). Names
+// are folded to lower case first, as PostgreSQL folds unquoted names, so
+// a mixed-case column or table name (UserID, Users) still resolves to the
+// lower-case object it always did; quoting only protects reserved words.
+func (Dialect) QuoteIdent(name string) string {
+	quoted := curd.QuoteIdentWith(name, '"')
+	if quoted == name {
+		return name
+	}
+	return strings.ToLower(quoted)
+}
+
+// SupportsReturning reports true: PostgreSQL supports INSERT ... RETURNING.
+func (Dialect) SupportsReturning() bool { return true }
+
+func (Dialect) LimitOffset(limit, offset string) string {
+	var clause string
+	if limit != "" {
+		clause += " LIMIT " + limit
+	}
+	if offset != "" {
+		clause += " OFFSET " + offset
+	}
+	return clause
+}
+
+// OnConflict renders ON CONFLICT (...) DO UPDATE SET col = EXCLUDED.col,
+// or DO NOTHING when updateCols is empty.
+func (d Dialect) OnConflict(conflictCols, updateCols []string) string {
+	target := ""
+	if len(conflictCols) > 0 {
+		quoted := make([]string, len(conflictCols))
+		for i, c := range conflictCols {
+			quoted[i] = d.QuoteIdent(c)
+		}
+		target = " (" + strings.Join(quoted, ",") + ")"
+	}
+	if len(updateCols) == 0 {
+		return " ON CONFLICT" + target + " DO NOTHING"
+	}
+	sets := make([]string, len(updateCols))
+	for i, c := range updateCols {
+		q := d.QuoteIdent(c)
+		sets[i] = q + " = EXCLUDED." + q
+	}
+	return " ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(sets, ",")
+}
@@ -1,6 +1,16 @@
 package postgres
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	curd "github.com/gobkc/do/curd"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 func TestDialectPlaceholder(t *testing.T) {
 	d := Dialect{}
@@ -21,3 +31,147 @@ func TestDialectPlaceholder(t *testing.T) {
 		}
 	}
 }
+
+func TestDialectQuoteIdent(t *testing.T) {
+	d := Dialect{}
+
+	tests := []struct {
+		in     string
+		expect string
+	}{
+		{"users", `"users"`},
+		{"public.users", `"public"."users"`},
+		{"order", `"order"`},
+		{"UserID", `"userid"`},
+		{"public.Users", `"public"."users"`},
+		{"count(*)", "count(*)"},
+		{"t.id AS tid", "t.id AS tid"},
+	}
+
+	for _, tt := range tests {
+		if got := d.QuoteIdent(tt.in); got != tt.expect {
+			t.Errorf("QuoteIdent(%q) = %q, want %q", tt.in, got, tt.expect)
+		}
+	}
+}
+
+func TestDialectLimitOffset(t *testing.T) {
+	d := Dialect{}
+
+	tests := []struct {
+		limit, offset string
+		expect        string
+	}{
+		{"", "", ""},
+		{"$1", "", " LIMIT $1"},
+		{"$1", "$2", " LIMIT $1 OFFSET $2"},
+		{"", "$1", " OFFSET $1"},
+	}
+
+	for _, tt := range tests {
+		if got := d.LimitOffset(tt.limit, tt.offset); got != tt.expect {
+			t.Errorf("LimitOffset(%q, %q) = %q, want %q", tt.limit, tt.offset, got, tt.expect)
+		}
+	}
+}
+
+func TestDialectOnConflict(t *testing.T) {
+	d := Dialect{}
+
+	got := d.OnConflict([]string{"email"}, []string{"name", "age"})
+	want := ` ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name","age" = EXCLUDED."age"`
+	if got != want {
+		t.Errorf("OnConflict update = %q, want %q", got, want)
+	}
+
+	got = d.OnConflict([]string{"email"}, nil)
+	want = ` ON CONFLICT ("email") DO NOTHING`
+	if got != want {
+		t.Errorf("OnConflict nothing = %q, want %q", got, want)
+	}
+}
+
+func TestDialectTranslateError(t *testing.T) {
+	d := Dialect{}
+	pgErr := &pgconn.PgError{
+		Code:           "23505",
+		TableName:      "users",
+		ConstraintName: "users_email_key",
+		Detail:         "Key (email)=(a@b.c) already exists.",
+	}
+	err := d.TranslateError(fmt.Errorf("insert users: %w", pgErr))
+	if !errors.Is(err, curd.ErrUniqueViolation) {
+		t.Fatalf("expected ErrUniqueViolation, got %v", err)
+	}
+	var dbErr *curd.DBError
+	if !errors.As(err, &dbErr) {
+		t.Fatalf("expected *curd.DBError, got %T", err)
+	}
+	if dbErr.Constraint != "users_email_key" || dbErr.Column != "email" || dbErr.Table != "users" {
+		t.Errorf("unexpected details: %+v", dbErr)
+	}
+	var back *pgconn.PgError
+	if !errors.As(err, &back) {
+		t.Error("translated error should unwrap to the pgconn.PgError")
+	}
+}
+
+func TestDialectTranslateErrorCodes(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"23503", curd.ErrForeignKeyViolation},
+		{"23514", curd.ErrCheckViolation},
+		{"40001", curd.ErrSerializationFailure},
+		{"40P01", curd.ErrDeadlock},
+	}
+	for _, tt := range tests {
+		err := Dialect{}.TranslateError(&pgconn.PgError{Code: tt.code})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("code %s: expected %v, got %v", tt.code, tt.want, err)
+		}
+	}
+
+	other := &pgconn.PgError{Code: "42601"}
+	if err := (Dialect{}).TranslateError(other); err != other {
+		t.Errorf("unknown codes should pass through, got %v", err)
+	}
+	if err := (Dialect{}).TranslateError(pgx.ErrNoRows); !errors.Is(err, curd.ErrNotFound) || !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected ErrNotFound wrapping pgx.ErrNoRows, got %v", err)
+	}
+}
+
+// mixedCase maps to the lower-case table and column PostgreSQL folds the
+// unquoted names to.
+type mixedCase struct {
+	UserID int64  `gorm:"column:UserID;primaryKey"`
+	Name   string `gorm:"column:Name"`
+}
+
+func (mixedCase) TableName() string { return "Users" }
+
+type sqlRecorder struct{ sql string }
+
+func (r *sqlRecorder) Query(context.Context, string, ...any) (curd.Rows, error) { return nil, nil }
+func (r *sqlRecorder) QueryRow(context.Context, string, ...any) curd.Row        { return nil }
+func (r *sqlRecorder) Exec(_ context.Context, sql string, _ ...any) (curd.Result, error) {
+	r.sql = sql
+	return rowsAffected(1), nil
+}
+
+type rowsAffected int64
+
+func (n rowsAffected) RowsAffected() int64 { return int64(n) }
+
+func TestMixedCaseNamesFoldLikeUnquotedSQL(t *testing.T) {
+	rec := &sqlRecorder{}
+	c := curd.New[mixedCase](rec, nil, Dialect{})
+	if err := c.UpdateByID(context.Background(), 1, map[string]any{"Name": "a"}); err != nil {
+		t.Fatalf("UpdateByID error: %v", err)
+	}
+	want := `UPDATE "users" SET "name" = $1 WHERE "userid" = $2`
+	if rec.sql != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", rec.sql, want)
+	}
+}
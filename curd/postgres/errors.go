@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	curd "github.com/gobkc/do/curd"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes translated by TranslateError.
+const (
+	codeUniqueViolation      = "23505"
+	codeForeignKeyViolation  = "23503"
+	codeCheckViolation       = "23514"
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// TranslateError maps PostgreSQL errors onto curd's error taxonomy by
+// SQLSTATE code, and pgx.ErrNoRows onto curd.ErrNotFound. It implements
+// curd.ErrorTranslator.
+func (Dialect) TranslateError(err error) error { return translateError(err) }
+
+// TranslateError implements curd.ErrorTranslator for QueryRaw, ExecRaw and
+// the other standalone functions. See Dialect.TranslateError.
+func (p *Pool) TranslateError(err error) error { return translateError(err) }
+
+// TranslateError implements curd.ErrorTranslator. See Dialect.TranslateError.
+func (t *txAdapter) TranslateError(err error) error { return translateError(err) }
+
+var (
+	_ curd.ErrorTranslator = Dialect{}
+	_ curd.ErrorTranslator = (*Pool)(nil)
+	_ curd.ErrorTranslator = (*txAdapter)(nil)
+	_ curd.ErrorTranslator = (*txOptionsBeginner)(nil)
+)
+
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: %w", curd.ErrNotFound, err)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	var kind error
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		kind = curd.ErrUniqueViolation
+	case codeForeignKeyViolation:
+		kind = curd.ErrForeignKeyViolation
+	case codeCheckViolation:
+		kind = curd.ErrCheckViolation
+	case codeSerializationFailure:
+		kind = curd.ErrSerializationFailure
+	case codeDeadlockDetected:
+		kind = curd.ErrDeadlock
+	default:
+		return err
+	}
+	return &curd.DBError{
+		Kind:       kind,
+		Table:      pgErr.TableName,
+		Constraint: pgErr.ConstraintName,
+		Column:     violationColumn(pgErr),
+		Err:        err,
+	}
+}
+
+// violationColumn returns the column reported by pgErr. PostgreSQL leaves
+// ColumnName empty for unique and foreign key violations, so the column
+// list is taken from the detail message instead:
+// "Key (email)=(a@b.c) already exists."
+func violationColumn(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	rest, ok := strings.CutPrefix(pgErr.Detail, "Key (")
+	if !ok {
+		return ""
+	}
+	cols, _, ok := strings.Cut(rest, ")=(")
+	if !ok {
+		return ""
+	}
+	return cols
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	curd "github.com/gobkc/do/curd"
@@ -143,12 +144,61 @@ func (p *Pool) BeginTx(ctx context.Context, opts pgx.TxOptions) (curd.Tx, error)
 	return &txAdapter{Tx: tx}, nil
 }
 
+// WithTxOptions returns a TxBeginner that starts transactions on p with
+// opts, e.g. for curd.WithTxRetry under SERIALIZABLE isolation. Curds using
+// p join its transactions as they join p's own (see curd.WithTx).
+//
+// Usage:
+//
+//	serializable := pool.WithTxOptions(pgx.TxOptions{IsoLevel: pgx.Serializable})
+//	err := curd.WithTxRetry(ctx, serializable, curd.TxRetryOptions{}, fn)
+func (p *Pool) WithTxOptions(opts pgx.TxOptions) curd.TxBeginner {
+	return &txOptionsBeginner{pool: p, opts: opts}
+}
+
+type txOptionsBeginner struct {
+	pool *Pool
+	opts pgx.TxOptions
+}
+
+func (b *txOptionsBeginner) Begin(ctx context.Context) (curd.Tx, error) {
+	return b.pool.BeginTx(ctx, b.opts)
+}
+
+// Unwrap returns the Pool, whose Curds share b's transactions.
+func (b *txOptionsBeginner) Unwrap() curd.TxBeginner { return b.pool }
+
+// TranslateError implements curd.ErrorTranslator. See Dialect.TranslateError.
+func (b *txOptionsBeginner) TranslateError(err error) error { return translateError(err) }
+
+// CopyFrom bulk-loads rows into table using the PostgreSQL COPY protocol.
+// It implements curd.BulkCopier. table may be schema-qualified ("public.items").
+func (p *Pool) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return p.pool.CopyFrom(ctx, copyIdentifier(table), columns, pgx.CopyFromRows(rows))
+}
+
 func (p *Pool) Close() {
 	p.pool.Close()
 }
 
+// copyIdentifier splits a possibly schema-qualified table name into a
+// pgx.Identifier, which quotes each part.
+func copyIdentifier(table string) pgx.Identifier {
+	return pgx.Identifier(strings.Split(table, "."))
+}
+
 type rowsAdapter struct{ pgx.Rows }
 
+// Columns implements curd.ColumnRows from the result's field descriptions.
+func (r *rowsAdapter) Columns() ([]string, error) {
+	fds := r.FieldDescriptions()
+	cols := make([]string, len(fds))
+	for i, fd := range fds {
+		cols[i] = fd.Name
+	}
+	return cols, nil
+}
+
 type rowAdapter struct{ pgx.Row }
 
 type resultAdapter struct {
@@ -182,3 +232,14 @@ func (t *txAdapter) Exec(ctx context.Context, sql string, args ...any) (curd.Res
 	}
 	return &resultAdapter{CommandTag: tag}, nil
 }
+
+// CopyFrom bulk-loads rows within the transaction using the COPY protocol.
+func (t *txAdapter) CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error) {
+	return t.Tx.CopyFrom(ctx, copyIdentifier(table), columns, pgx.CopyFromRows(rows))
+}
+
+var (
+	_ curd.BulkCopier = (*Pool)(nil)
+	_ curd.BulkCopier = (*txAdapter)(nil)
+	_ curd.ColumnRows = (*rowsAdapter)(nil)
+)
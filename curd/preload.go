@@ -0,0 +1,352 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Relations are struct fields tagged with their kind, loaded by
+// WithPreload instead of being mapped to a column:
+//
+//	type Order struct {
+//	    ID         int64        `json:"id"`
+//	    CustomerID int64        `json:"customer_id"`
+//	    Customer   *Customer    `json:"customer" curd:"belongs_to"`
+//	    Items      []OrderItem  `json:"items" curd:"has_many"`
+//	    Invoice    *Invoice     `json:"invoice" curd:"has_one;fk:order_ref;ref:id"`
+//	}
+//
+// belongs_to: the parent holds the foreign key fk (default: field name +
+// "_id", e.g. customer_id) referencing ref on the related table (default:
+// its primary key). has_one and has_many: the related table holds fk
+// (default: parent type name + "_id", e.g. order_id) referencing ref on the
+// parent (default: its primary key). The field is a struct or pointer for
+// belongs_to and has_one, and a slice of structs or pointers for has_many.
+// The related type must implement Table.
+
+type relationKind int
+
+const (
+	belongsTo relationKind = iota
+	hasOne
+	hasMany
+)
+
+var relationTags = map[string]relationKind{"belongs_to": belongsTo, "has_one": hasOne, "has_many": hasMany}
+
+// relation is a relation field resolved against its parent type.
+type relation struct {
+	name  string
+	index []int
+	kind  relationKind
+	elem  reflect.Type // related struct type
+	ptr   bool         // the field (or slice element) is a pointer
+	fk    string       // foreign-key column
+	ref   string       // referenced column
+}
+
+// isRelation reports whether f is a relation field.
+func isRelation(f reflect.StructField) bool {
+	_, ok := relationKindOf(f)
+	return ok
+}
+
+func relationKindOf(f reflect.StructField) (relationKind, bool) {
+	for tag, kind := range relationTags {
+		if hasTagOption(f, "curd", tag) {
+			return kind, true
+		}
+	}
+	return 0, false
+}
+
+// tagValue returns the value of the "name:value" option in the
+// ;-separated struct tag key.
+func tagValue(f reflect.StructField, key, name string) (string, bool) {
+	for _, part := range strings.Split(f.Tag.Get(key), ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(k), name) {
+			return strings.TrimSpace(v), true
+		}
+	}
+	return "", false
+}
+
+// relationOf resolves the relation field name of struct type parent.
+func relationOf(parent reflect.Type, name string, fm FieldMapper) (*relation, error) {
+	f, ok := fieldByName(parent, name)
+	if !ok {
+		return nil, fmt.Errorf("%s has no field %q", parent.Name(), name)
+	}
+	kind, ok := relationKindOf(f.StructField)
+	if !ok {
+		return nil, fmt.Errorf("%s.%s is not tagged as a relation", parent.Name(), name)
+	}
+	rel := &relation{name: name, index: f.Index, kind: kind}
+	t := f.Type
+	if kind == hasMany {
+		if t.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("has_many %s.%s must be a slice", parent.Name(), name)
+		}
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		rel.ptr = true
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("relation %s.%s must hold structs", parent.Name(), name)
+	}
+	if _, ok := reflect.New(t).Interface().(Table); !ok {
+		return nil, fmt.Errorf("relation %s.%s: %s does not implement Table", parent.Name(), name, t.Name())
+	}
+	rel.elem = t
+
+	rel.fk, _ = tagValue(f.StructField, "curd", "fk")
+	rel.ref, _ = tagValue(f.StructField, "curd", "ref")
+	keyOwner := parent
+	if kind == belongsTo {
+		keyOwner = t
+		if rel.fk == "" {
+			rel.fk = toSnakeCase(name) + "_id"
+		}
+	} else if rel.fk == "" {
+		rel.fk = toSnakeCase(parent.Name()) + "_id"
+	}
+	if rel.ref == "" {
+		pk := primaryKeyFields(keyOwner, fm)
+		switch len(pk) {
+		case 0:
+			rel.ref = "id"
+		case 1:
+			rel.ref = pk[0].column
+		default:
+			return nil, fmt.Errorf("relation %s.%s: %s has a composite key, set ref", parent.Name(), name, keyOwner.Name())
+		}
+	}
+	return rel, nil
+}
+
+// WithPreload loads the named relation fields of the result rows, one
+// batched IN (...) query per relation instead of one query per row. Nested
+// relations are written with dots ("Items.Product"); the parent relation is
+// loaded implicitly. Soft-deleted related rows are not loaded.
+//
+// It applies to Find, FindPaginated and FindAfter; Iter, FindInto and
+// FindPaginatedInto return an error when given it.
+//
+// Usage:
+//
+//	orders, err := c.Find(ctx,
+//	    curd.WithWhere(curd.Eq("status", "open")),
+//	    curd.WithPreload("Customer", "Items.Product"),
+//	)
+func WithPreload(relations ...string) FindOption {
+	return func(c *findConfig) { c.preload = append(c.preload, relations...) }
+}
+
+// preloadRows loads the relations named by paths into rows, a slice of T.
+func (c *Curd[T]) preloadRows(ctx context.Context, rows []T, paths []string) error {
+	if len(rows) == 0 || len(paths) == 0 {
+		return nil
+	}
+	list := reflect.ValueOf(rows)
+	parents := make([]reflect.Value, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if v := derefValue(list.Index(i)); v.IsValid() {
+			parents = append(parents, v)
+		}
+	}
+	if err := c.preloadLevel(ctx, parents, derefType(reflect.TypeFor[T]()), paths); err != nil {
+		return fmt.Errorf("preload %s: %w", tableName[T](), err)
+	}
+	return nil
+}
+
+// preloadLevel loads the first segment of each path into parents (struct
+// values of type t), then the remaining segments into the loaded rows.
+func (c *Curd[T]) preloadLevel(ctx context.Context, parents []reflect.Value, t reflect.Type, paths []string) error {
+	var names []string
+	nested := map[string][]string{}
+	for _, p := range paths {
+		name, rest, _ := strings.Cut(p, ".")
+		if _, seen := nested[name]; !seen {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+	for _, name := range names {
+		rel, err := relationOf(t, name, c.fm)
+		if err != nil {
+			return err
+		}
+		if err := c.loadRelation(ctx, parents, rel, nested[name]); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// loadRelation queries the rows related to parents, preloads their nested
+// paths and stitches them into the relation field of each parent.
+func (c *Curd[T]) loadRelation(ctx context.Context, parents []reflect.Value, rel *relation, nested []string) error {
+	// The parent column whose values are looked up, and the related column
+	// they are matched against.
+	parentCol, relatedCol := rel.ref, rel.fk
+	if rel.kind == belongsTo {
+		parentCol, relatedCol = rel.fk, rel.ref
+	}
+
+	var keys []any
+	seen := map[any]bool{}
+	for _, p := range parents {
+		f, ok := fieldByColumn(p, c.fm, parentCol)
+		if !ok {
+			return fmt.Errorf("%s has no column %q", p.Type().Name(), parentCol)
+		}
+		if k, ok := relationKey(f); ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, derefValue(f).Interface())
+		}
+	}
+	if _, ok := fieldByColumn(reflect.New(rel.elem), c.fm, relatedCol); !ok {
+		return fmt.Errorf("%s has no column %q", rel.elem.Name(), relatedCol)
+	}
+
+	related, err := c.queryRelated(ctx, rel.elem, relatedCol, keys)
+	if err != nil {
+		return err
+	}
+	if len(nested) > 0 && len(related) > 0 {
+		structs := make([]reflect.Value, len(related))
+		for i, r := range related {
+			structs[i] = r.Elem()
+		}
+		if err := c.preloadLevel(ctx, structs, rel.elem, nested); err != nil {
+			return err
+		}
+	}
+
+	byKey := map[any][]reflect.Value{}
+	for _, r := range related {
+		f, _ := fieldByColumn(r, c.fm, relatedCol)
+		if k, ok := relationKey(f); ok {
+			byKey[k] = append(byKey[k], r)
+		}
+	}
+	for _, p := range parents {
+		f, _ := fieldByColumn(p, c.fm, parentCol)
+		k, _ := relationKey(f)
+		matches := byKey[k]
+		field := settableField(p, rel.index)
+		if rel.kind == hasMany {
+			list := reflect.MakeSlice(field.Type(), 0, len(matches))
+			for _, m := range matches {
+				list = reflect.Append(list, relatedValue(m, rel.ptr))
+			}
+			field.Set(list)
+			continue
+		}
+		if len(matches) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		field.Set(relatedValue(matches[0], rel.ptr))
+	}
+	return nil
+}
+
+// queryRelated selects the live rows of struct type t whose column matches
+// one of keys, returning pointers to them.
+func (c *Curd[T]) queryRelated(ctx context.Context, t reflect.Type, column string, keys []any) ([]reflect.Value, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	table := reflect.New(t).Interface().(Table).TableName()
+	cols := c.quoteAll(columnsFromType(t, c.fm))
+	var filter string
+	if sd := resolveSoftDelete(t, c.fm, c.sdPolicy); sd != nil && sd.onField {
+		filter = " AND " + sd.liveSQL(c.quote(sd.column))
+	}
+
+	var related []reflect.Value
+	for start := 0; start < len(keys); start += maxBindParams {
+		chunk := keys[start:min(start+maxBindParams, len(keys))]
+		b := newArgBuilder(c.dialect, 1)
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN (%s)%s",
+			strings.Join(cols, ","), c.quote(table), c.quote(column), b.Args(chunk...), filter)
+		args := b.ArgsSlice()
+		rows, err := c.queryRelatedChunk(ctx, t, query, args)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", table, err)
+		}
+		related = append(related, rows...)
+	}
+	return related, nil
+}
+
+func (c *Curd[T]) queryRelatedChunk(ctx context.Context, t reflect.Type, query string, args []any) ([]reflect.Value, error) {
+	defer c.logSQL(ctx, query, args...)()
+	rows, err := c.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var related []reflect.Value
+	d := c.decoder()
+	for rows.Next() {
+		elem := reflect.New(t)
+		if err := scanInto(ctx, rows, elem, c.fm, d); err != nil {
+			return nil, err
+		}
+		related = append(related, elem)
+	}
+	return related, rows.Err()
+}
+
+// relatedValue converts the loaded row ptr (a *U) to the relation's field or
+// element type.
+func relatedValue(ptr reflect.Value, wantPtr bool) reflect.Value {
+	if wantPtr {
+		return ptr
+	}
+	return ptr.Elem()
+}
+
+// relationKey normalises a key field value for matching parents to related
+// rows: pointers are dereferenced and integers widened, so an int32
+// foreign key matches an int64 primary key. It reports false for NULL.
+func relationKey(f reflect.Value) (any, bool) {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil, false
+		}
+		f = f.Elem()
+	}
+	switch {
+	case !f.IsValid():
+		return nil, false
+	case f.CanInt():
+		return f.Int(), true
+	case f.CanUint():
+		return int64(f.Uint()), true
+	case f.Kind() == reflect.String:
+		return f.String(), true
+	case f.Comparable():
+		return f.Interface(), true
+	}
+	return fmt.Sprint(f.Interface()), true
+}
+
+// derefType returns t with pointer indirections removed.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,224 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// ColumnRows is implemented by Rows that report the names of their result
+// columns, such as *sql.Rows. FindInto maps result columns to fields by
+// these names; for other Rows it relies on the order of its select list.
+type ColumnRows interface {
+	Columns() ([]string, error)
+}
+
+// FindInto runs Find over T's table — its predicate, joins, soft-delete
+// scope, order and page — but scans each row into the projection type R,
+// matching result columns to R's columns by name. Result columns R lacks
+// are discarded; R's fields without a result column are left zero.
+// WithPreload is rejected, as R has no relation fields to load.
+//
+// Without WithColumns, every column of R must be a column of T. WithColumns
+// may also select columns of joined tables, qualified with the table or its
+// alias, and rename a column with AS; a joined column must land in a column
+// of R, under its own name or its alias (see ErrUnknownColumn):
+//
+//	type orderView struct {
+//	    ID     int64   `json:"id"`
+//	    Amount float64 `json:"amount"`
+//	    Label  string  `json:"label"`
+//	    Region string  `json:"region"`
+//	}
+//	views, err := curd.FindInto[orderView](ctx, orders,
+//	    curd.WithJoins(curd.JoinClause{Type: curd.LeftJoin, Table: "regions AS r", On: "r.id = orders.region_id"}),
+//	    curd.WithColumns("orders.id", "amount", "r.label", "r.name AS region"),
+//	    curd.WithOrder(curd.Asc("r.label")),
+//	)
+func FindInto[R any, T Table](ctx context.Context, c *Curd[T], opts ...FindOption) ([]R, error) {
+	return findInto[R](ctx, c, resolveFindConfig(opts))
+}
+
+// FindPaginatedInto is FindInto with the total count of FindPaginated.
+func FindPaginatedInto[R any, T Table](ctx context.Context, c *Curd[T], opts ...FindOption) (*PaginatedResult[R], error) {
+	cfg := resolveFindConfig(opts)
+	total, err := c.countFound(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	list, err := findInto[R](ctx, c, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &PaginatedResult[R]{List: list, Total: total}, nil
+}
+
+func findInto[R any, T Table](ctx context.Context, c *Curd[T], cfg *findConfig) ([]R, error) {
+	name := tableName[T]()
+	if len(cfg.preload) > 0 {
+		return nil, fmt.Errorf("find %s: WithPreload is not supported by FindInto", name)
+	}
+	b := newArgBuilder(c.dialect, 1)
+	query, names, err := buildProjection[R](ctx, c, cfg, b)
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", name, err)
+	}
+	args := b.ArgsSlice()
+	defer c.logSQL(ctx, query, args...)()
+	rows, err := c.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", name, err)
+	}
+	defer rows.Close()
+	results, err := scanByName[R](ctx, rows, names, c.fm, c.decoder())
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", name, err)
+	}
+	return results, nil
+}
+
+// buildProjection renders the SELECT of FindInto and returns the names of
+// its result columns.
+func buildProjection[R any, T Table](ctx context.Context, c *Curd[T], cfg *findConfig, b *ArgBuilder) (string, []string, error) {
+	var r R
+	outputs := columnsFromType(reflect.TypeOf(r), c.fm)
+	if len(outputs) == 0 {
+		return "", nil, fmt.Errorf("result type %T has no columns", r)
+	}
+
+	var list, names []string
+	if len(cfg.columns) == 0 {
+		// Qualify T's columns when joined tables may share their names.
+		qualifier := ""
+		if len(cfg.joins) > 0 {
+			qualifier = tableName[T]() + "."
+		}
+		for _, col := range outputs {
+			resolved, err := c.columns.resolve(col)
+			if err != nil {
+				return "", nil, fmt.Errorf("result column %q is not a column of %s; select it with WithColumns: %w",
+					col, tableName[T](), err)
+			}
+			list = append(list, c.quote(qualifier+resolved))
+			names = append(names, col)
+		}
+	} else {
+		// Selected and ordered columns may be columns of T or of R.
+		results := make(columnSet, len(outputs))
+		for _, col := range outputs {
+			results[col] = col
+		}
+		scoped := *c
+		scoped.columns = maps.Clone(results)
+		maps.Copy(scoped.columns, c.columns)
+		for _, spec := range cfg.columns {
+			expr, alias, err := scoped.resolveProjected(spec, results)
+			if err != nil {
+				return "", nil, err
+			}
+			if alias == "" {
+				list = append(list, c.quote(expr))
+				names = append(names, expr[strings.LastIndexByte(expr, '.')+1:])
+				continue
+			}
+			list = append(list, c.quote(expr)+" AS "+c.quote(alias))
+			names = append(names, alias)
+		}
+		c = &scoped
+	}
+
+	query, err := c.renderSelectList(ctx, strings.Join(list, ","), cfg, b)
+	if err != nil {
+		return "", nil, err
+	}
+	return query, names, nil
+}
+
+// resolveProjected resolves a WithColumns entry of FindInto: a column,
+// optionally followed by AS and an alias. Columns of T's table resolve
+// against c's columns; the columns of joined tables are unknown, so their
+// result name (alias or column) must be one of results.
+func (c *Curd[T]) resolveProjected(spec string, results columnSet) (expr, alias string, err error) {
+	words := strings.Fields(spec)
+	switch {
+	case len(words) == 3 && strings.EqualFold(words[1], "AS"):
+		alias = words[2]
+		if !isPlainIdent(alias) || strings.Contains(alias, ".") {
+			return "", "", fmt.Errorf("invalid column alias %q", alias)
+		}
+	case len(words) != 1:
+		return "", "", fmt.Errorf("%w %q", ErrUnknownColumn, spec)
+	}
+	expr = words[0]
+	idx := strings.LastIndexByte(expr, '.')
+	if idx < 0 || expr[:idx] == tableName[T]() {
+		expr, err = c.columns.resolve(expr)
+		return expr, alias, err
+	}
+	name := alias
+	if name == "" {
+		name = expr[idx+1:]
+	}
+	if _, ok := results[name]; !ok || !isPlainIdent(expr) {
+		return "", "", fmt.Errorf("%w %q", ErrUnknownColumn, spec)
+	}
+	return expr, alias, nil
+}
+
+// scanByName scans rows into a []R, assigning each result column to the
+// field of R mapped to the same column name. names are the result column
+// names used when rows does not implement ColumnRows.
+func scanByName[R any](ctx context.Context, rows Rows, names []string, fm FieldMapper, d FieldDecoder) ([]R, error) {
+	if cr, ok := rows.(ColumnRows); ok {
+		cols, err := cr.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		names = cols
+	}
+	elemType := reflect.TypeFor[R]()
+	byColumn := map[string]flatField{}
+	for _, f := range flatFields(elemType) {
+		if col := f.columnName(fm); col != "" {
+			byColumn[col] = f
+		}
+	}
+
+	var results []R
+	for rows.Next() {
+		elem := newT[R]()
+		s := derefValue(elem)
+
+		dest := make([]any, len(names))
+		var columns []string
+		var fields []reflect.Value
+		var targets []any
+		for i, name := range names {
+			var v any
+			dest[i] = &v
+			f, ok := byColumn[name]
+			if !ok {
+				continue
+			}
+			if fv := settableField(s, f.Index); fv.IsValid() {
+				columns = append(columns, name)
+				fields = append(fields, fv)
+				targets = append(targets, &v)
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		if err := decodeTargets(d, columns, fields, targets); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		nullSafeCopy(fields, targets)
+		if err := afterFind(ctx, elem); err != nil {
+			return nil, fmt.Errorf("after find: %w", err)
+		}
+		results = append(results, elem.Interface().(R))
+	}
+	return results, rows.Err()
+}
@@ -14,9 +14,18 @@ type Row interface {
 }
 
 type Result interface {
+	// RowsAffected returns the number of rows the statement affected, or
+	// -1 when the driver cannot report it.
 	RowsAffected() int64
 }
 
+// LastInsertIDResult is implemented by Results that expose the id generated
+// by an INSERT (e.g. MySQL AUTO_INCREMENT). InsertOne uses it when the
+// Dialect does not support RETURNING.
+type LastInsertIDResult interface {
+	LastInsertId() (int64, error)
+}
+
 type Querier interface {
 	Query(ctx context.Context, sql string, args ...any) (Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) Row
@@ -29,6 +38,13 @@ type Tx interface {
 	Rollback(ctx context.Context) error
 }
 
+// BulkCopier is implemented by Queriers that support a native bulk-load
+// protocol such as PostgreSQL COPY. Curd.InsertBatch uses it for large
+// batches. rows hold one value per column, in column order.
+type BulkCopier interface {
+	CopyFrom(ctx context.Context, table string, columns []string, rows [][]any) (int64, error)
+}
+
 type TxBeginner interface {
 	Begin(ctx context.Context) (Tx, error)
 }
@@ -0,0 +1,22 @@
+module github.com/gobkc/do/curd/queue
+
+go 1.25.0
+
+require (
+	github.com/gobkc/do/curd v0.0.0-20260624183304-3de19f2da4dd
+	github.com/gobkc/do/curd/postgres v0.0.0-20260624183304-3de19f2da4dd
+)
+
+require (
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/pgx/v5 v5.10.0 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
+
+replace (
+	github.com/gobkc/do/curd => ..
+	github.com/gobkc/do/curd/postgres => ../postgres
+)
@@ -0,0 +1,373 @@
+// Package queue is a durable job queue stored in a PostgreSQL table and
+// accessed through curd: jobs are enqueued with a payload, priority and
+// run-at time, claimed with SELECT ... FOR UPDATE SKIP LOCKED, retried with
+// exponential backoff and dead-lettered once their attempts run out.
+//
+// Usage:
+//
+//	pool, _ := postgres.NewPool(dsn)
+//	q := queue.New(pool)
+//	if err := q.Migrate(ctx); err != nil { ... }
+//
+//	w := queue.NewWorker(deps, q)
+//	w.Route("send_email", 4, time.Minute, func(ctx context.Context, deps *Deps, job *queue.Job) error {
+//	    var msg Email
+//	    if err := job.Decode(&msg); err != nil {
+//	        return err
+//	    }
+//	    return deps.Mailer.Send(ctx, msg)
+//	})
+//	w.Start()
+//	defer w.Stop()
+//
+//	q.Enqueue(ctx, "send_email", Email{To: "a@example.com"}, queue.Priority(10))
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	curd "github.com/gobkc/do/curd"
+	"github.com/gobkc/do/curd/postgres"
+)
+
+// Job statuses.
+const (
+	StatusPending   = "pending"   // waiting for its run_at time
+	StatusRunning   = "running"   // claimed by a worker until locked_until
+	StatusCompleted = "completed" // handled successfully
+	StatusDead      = "dead"      // failed its last attempt
+)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultBackoffBase  = 5 * time.Second
+	defaultBackoffMax   = time.Hour
+	defaultPollInterval = time.Second
+)
+
+// Schema creates the jobs table and its claim index. Migrate runs it.
+const Schema = `
+CREATE TABLE IF NOT EXISTS queue_jobs (
+	id           BIGSERIAL PRIMARY KEY,
+	queue        TEXT        NOT NULL,
+	payload      JSONB       NOT NULL DEFAULT '{}',
+	priority     INT         NOT NULL DEFAULT 0,
+	status       TEXT        NOT NULL DEFAULT 'pending',
+	attempts     INT         NOT NULL DEFAULT 0,
+	max_attempts INT         NOT NULL DEFAULT 5,
+	run_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	locked_until TIMESTAMPTZ,
+	last_error   TEXT,
+	version      BIGINT      NOT NULL DEFAULT 0,
+	created_date TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	changed_date TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS queue_jobs_claim_idx ON queue_jobs (queue, status, priority DESC, run_at);`
+
+// Job is a row of the jobs table.
+type Job struct {
+	ID          int64           `json:"id"`
+	Queue       string          `json:"queue"`
+	Payload     json.RawMessage `json:"payload"`
+	Priority    int             `json:"priority"` // higher runs first
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"` // claims so far, including the current one
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedUntil *time.Time      `json:"locked_until"` // visibility deadline of a running job
+	LastError   *string         `json:"last_error"`
+	Version     int64           `json:"version" curd:"version"` // fences updates by a worker whose claim expired
+	CreatedDate time.Time       `json:"created_date"`
+	ChangedDate time.Time       `json:"changed_date"`
+}
+
+func (Job) TableName() string { return "queue_jobs" }
+
+// Decode JSON-decodes the job's payload into v.
+func (j *Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("decode job %d payload: %w", j.ID, err)
+	}
+	return nil
+}
+
+// DB is the database a Queue runs on, such as a *postgres.Pool.
+type DB interface {
+	curd.Querier
+	curd.TxBeginner
+}
+
+// Option configures a Queue.
+type Option func(*config)
+
+type config struct {
+	maxAttempts  int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// MaxAttempts sets how many times a job is attempted before it is
+// dead-lettered, unless Enqueue overrides it. Default 5.
+func MaxAttempts(n int) Option {
+	return func(c *config) { c.maxAttempts = n }
+}
+
+// Backoff sets the retry delay after a failed attempt: base doubled per
+// attempt, capped at max, plus up to 50% jitter. Default 5s to 1h.
+func Backoff(base, max time.Duration) Option {
+	return func(c *config) { c.backoffBase, c.backoffMax = base, max }
+}
+
+// PollInterval sets how often an idle route looks for due jobs. Default 1s.
+func PollInterval(d time.Duration) Option {
+	return func(c *config) { c.pollInterval = d }
+}
+
+// Queue enqueues and manages jobs. It is safe for concurrent use.
+type Queue struct {
+	db   DB
+	jobs *curd.Curd[*Job]
+	cfg  *config
+}
+
+// New creates a Queue on db.
+func New(db DB, opts ...Option) *Queue {
+	cfg := &config{
+		maxAttempts:  defaultMaxAttempts,
+		backoffBase:  defaultBackoffBase,
+		backoffMax:   defaultBackoffMax,
+		pollInterval: defaultPollInterval,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	jobs := curd.New[*Job](db, nil, postgres.Dialect{}).WithDecoder(curd.JSONBUnmarshaler("payload"))
+	return &Queue{db: db, jobs: jobs, cfg: cfg}
+}
+
+// WithQuerier returns a Queue that enqueues through q, e.g. the transaction
+// of a WithTx function, so a job is only queued if the transaction commits.
+// It is not needed within a curd.WithTx on the Queue's DB, whose transaction
+// Enqueue picks up from the context.
+func (q *Queue) WithQuerier(tx curd.Querier) *Queue {
+	cp := *q
+	cp.jobs = q.jobs.WithQuerier(tx)
+	return &cp
+}
+
+// Migrate creates the jobs table if it does not exist.
+func (q *Queue) Migrate(ctx context.Context) error {
+	if _, err := q.db.Exec(ctx, Schema); err != nil {
+		return fmt.Errorf("migrate queue: %w", err)
+	}
+	return nil
+}
+
+// EnqueueOption configures a job passed to Enqueue.
+type EnqueueOption func(*Job)
+
+// Priority sets the job's priority; higher-priority due jobs are claimed
+// first. Default 0.
+func Priority(p int) EnqueueOption {
+	return func(j *Job) { j.Priority = p }
+}
+
+// RunAt delays the job until t.
+func RunAt(t time.Time) EnqueueOption {
+	return func(j *Job) { j.RunAt = t }
+}
+
+// Delay delays the job by d.
+func Delay(d time.Duration) EnqueueOption {
+	return func(j *Job) { j.RunAt = j.RunAt.Add(d) }
+}
+
+// Attempts overrides the Queue's MaxAttempts for the job.
+func Attempts(n int) EnqueueOption {
+	return func(j *Job) { j.MaxAttempts = n }
+}
+
+// Enqueue adds a job for the route name. payload is JSON-encoded; a
+// json.RawMessage is stored as is.
+func (q *Queue) Enqueue(ctx context.Context, name string, payload any, opts ...EnqueueOption) (*Job, error) {
+	raw, ok := payload.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("enqueue %s: encode payload: %w", name, err)
+		}
+	}
+	job := &Job{
+		Queue:       name,
+		Payload:     raw,
+		Status:      StatusPending,
+		MaxAttempts: q.cfg.maxAttempts,
+		RunAt:       q.cfg.now(),
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := q.jobs.InsertOne(ctx, &job); err != nil {
+		return nil, fmt.Errorf("enqueue %s: %w", name, err)
+	}
+	return job, nil
+}
+
+// Dead returns up to limit dead-lettered jobs of route name, most recent
+// first.
+func (q *Queue) Dead(ctx context.Context, name string, limit int) ([]*Job, error) {
+	return q.jobs.Find(ctx,
+		curd.WithWhere(curd.And(curd.Eq("queue", name), curd.Eq("status", StatusDead))),
+		curd.WithOrder(curd.Desc("changed_date"), curd.Desc("id")),
+		curd.WithLimit(limit),
+	)
+}
+
+// Retry requeues the dead-lettered job id with a fresh set of attempts. It
+// fails with curd.ErrNotFound when id is not a dead job.
+func (q *Queue) Retry(ctx context.Context, id int64) error {
+	err := curd.WithTx(ctx, q.db, func(ctx context.Context, tx curd.Querier) error {
+		jobs := q.jobs.WithQuerier(tx)
+		dead := curd.And(curd.Eq("id", id), curd.Eq("status", StatusDead))
+		found, err := jobs.Find(ctx, curd.WithWhere(dead), curd.WithLock(curd.ForUpdate))
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return curd.ErrNotFound
+		}
+		return jobs.UpdateWhere(ctx, dead, map[string]any{
+			"status":       StatusPending,
+			"attempts":     0,
+			"run_at":       q.cfg.now(),
+			"locked_until": nil,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("retry job %d: %w", id, err)
+	}
+	return nil
+}
+
+// claim locks up to limit due jobs of route name — pending jobs whose
+// run_at has passed and running jobs whose visibility timeout expired —
+// and marks them running until now+visibility. Expired jobs without
+// attempts left are dead-lettered instead of returned.
+func (q *Queue) claim(ctx context.Context, name string, limit int, visibility time.Duration) ([]*Job, error) {
+	return curd.WithTxResult(ctx, q.db, func(ctx context.Context, tx curd.Querier) ([]*Job, error) {
+		jobs := q.jobs.WithQuerier(tx)
+		now := q.cfg.now()
+		due, err := jobs.Find(ctx,
+			curd.WithWhere(curd.And(
+				curd.Eq("queue", name),
+				curd.Or(
+					curd.And(curd.Eq("status", StatusPending), curd.Lte("run_at", now)),
+					curd.And(curd.Eq("status", StatusRunning), curd.Lte("locked_until", now)),
+				),
+			)),
+			curd.WithOrder(curd.Desc("priority"), curd.Asc("run_at"), curd.Asc("id")),
+			curd.WithLimit(limit),
+			curd.WithLock(curd.ForUpdate, curd.SkipLocked),
+		)
+		if err != nil {
+			return nil, err
+		}
+		claimed := due[:0]
+		for _, j := range due {
+			if j.Attempts >= j.MaxAttempts {
+				if err := q.finish(ctx, jobs, j, StatusDead, "visibility timeout expired", now); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			until := now.Add(visibility)
+			err := jobs.UpdateByID(ctx, j.ID, map[string]any{
+				"status":       StatusRunning,
+				"attempts":     j.Attempts + 1,
+				"locked_until": until,
+				"version":      j.Version,
+			})
+			if err != nil {
+				return nil, err
+			}
+			j.Status, j.Attempts, j.LockedUntil, j.Version = StatusRunning, j.Attempts+1, &until, j.Version+1
+			claimed = append(claimed, j)
+		}
+		return claimed, nil
+	})
+}
+
+// extend pushes the visibility deadline of the running job j to
+// now+visibility. It fails with curd.ErrStaleObject when j was reclaimed.
+func (q *Queue) extend(ctx context.Context, j *Job, visibility time.Duration) error {
+	until := q.cfg.now().Add(visibility)
+	if err := q.jobs.UpdateByID(ctx, j.ID, map[string]any{"locked_until": until, "version": j.Version}); err != nil {
+		return err
+	}
+	j.LockedUntil = &until
+	j.Version++
+	return nil
+}
+
+// complete marks j completed, or schedules its retry after a failed
+// attempt, dead-lettering it when no attempts are left.
+func (q *Queue) complete(ctx context.Context, j *Job, failure error) error {
+	now := q.cfg.now()
+	switch {
+	case failure == nil:
+		return q.finish(ctx, q.jobs, j, StatusCompleted, "", now)
+	case j.Attempts >= j.MaxAttempts:
+		return q.finish(ctx, q.jobs, j, StatusDead, failure.Error(), now)
+	}
+	msg := failure.Error()
+	runAt := now.Add(q.backoff(j.Attempts))
+	err := q.jobs.UpdateByID(ctx, j.ID, map[string]any{
+		"status":       StatusPending,
+		"run_at":       runAt,
+		"locked_until": nil,
+		"last_error":   msg,
+		"version":      j.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("retry job %d: %w", j.ID, err)
+	}
+	j.Status, j.RunAt, j.LockedUntil, j.LastError, j.Version = StatusPending, runAt, nil, &msg, j.Version+1
+	return nil
+}
+
+func (q *Queue) finish(ctx context.Context, jobs *curd.Curd[*Job], j *Job, status, msg string, now time.Time) error {
+	updates := map[string]any{
+		"status":       status,
+		"locked_until": nil,
+		"version":      j.Version,
+	}
+	if msg != "" {
+		updates["last_error"] = msg
+		j.LastError = &msg
+	}
+	if err := jobs.UpdateByID(ctx, j.ID, updates); err != nil {
+		return fmt.Errorf("%s job %d: %w", status, j.ID, err)
+	}
+	j.Status, j.LockedUntil, j.Version = status, nil, j.Version+1
+	return nil
+}
+
+// backoff returns the delay before the retry following attempt (1-based).
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.cfg.backoffBase
+	for i := 1; i < attempt && delay < q.cfg.backoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, q.cfg.backoffMax)
+	if delay <= 1 {
+		return delay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay/2)))
+}
@@ -0,0 +1,339 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	curd "github.com/gobkc/do/curd"
+	"github.com/gobkc/do/curd/postgres"
+)
+
+var _ DB = (*postgres.Pool)(nil)
+
+// ============================================
+// Mocks
+// ============================================
+
+type fakeRows struct {
+	records [][]any
+	pos     int
+}
+
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+func (r *fakeRows) Next() bool { r.pos++; return r.pos <= len(r.records) }
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		*d.(*any) = r.records[r.pos-1][i]
+	}
+	return nil
+}
+
+type fakeRow struct{ id int64 }
+
+func (r fakeRow) Scan(dest ...any) error {
+	*dest[0].(*int64) = r.id
+	return nil
+}
+
+type fakeResult int64
+
+func (r fakeResult) RowsAffected() int64 { return int64(r) }
+
+// fakeDB records statements; Query serves rows once, Exec affects affected
+// rows.
+type fakeDB struct {
+	mu        sync.Mutex
+	sqls      []string
+	args      [][]any
+	rows      [][]any
+	affected  int64
+	committed int
+}
+
+func (db *fakeDB) record(sql string, args []any) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.sqls = append(db.sqls, sql)
+	db.args = append(db.args, args)
+}
+
+func (db *fakeDB) Query(_ context.Context, sql string, args ...any) (curd.Rows, error) {
+	db.record(sql, args)
+	rows := db.rows
+	db.rows = nil
+	return &fakeRows{records: rows}, nil
+}
+
+func (db *fakeDB) QueryRow(_ context.Context, sql string, args ...any) curd.Row {
+	db.record(sql, args)
+	return fakeRow{id: 1}
+}
+
+func (db *fakeDB) Exec(_ context.Context, sql string, args ...any) (curd.Result, error) {
+	db.record(sql, args)
+	return fakeResult(db.affected), nil
+}
+
+func (db *fakeDB) Begin(context.Context) (curd.Tx, error) { return &fakeTx{db}, nil }
+
+type fakeTx struct{ *fakeDB }
+
+func (tx *fakeTx) Commit(context.Context) error   { tx.committed++; return nil }
+func (tx *fakeTx) Rollback(context.Context) error { return nil }
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestQueue(db *fakeDB, opts ...Option) *Queue {
+	q := New(db, opts...)
+	q.cfg.now = func() time.Time { return testNow }
+	return q
+}
+
+// jobRecord is a scanned queue_jobs row in column order.
+func jobRecord(id int64, status string, attempts, maxAttempts int, version int64) []any {
+	return []any{id, "mail", []byte(`{"to":"a"}`), 0, status, attempts, maxAttempts, testNow, nil, nil, version, testNow, testNow}
+}
+
+// ============================================
+// Queue Tests
+// ============================================
+
+func TestEnqueue(t *testing.T) {
+	db := &fakeDB{}
+	q := newTestQueue(db, MaxAttempts(3))
+
+	job, err := q.Enqueue(context.Background(), "mail", map[string]string{"to": "a"}, Priority(5), Delay(time.Minute))
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	want := `INSERT INTO "queue_jobs" ("queue","payload","priority","status","attempts","max_attempts","run_at","locked_until","last_error","version","created_date","changed_date")` +
+		` VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING "id"`
+	if db.sqls[0] != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", db.sqls[0], want)
+	}
+	if job.ID != 1 || job.Priority != 5 || job.MaxAttempts != 3 || !job.RunAt.Equal(testNow.Add(time.Minute)) {
+		t.Errorf("unexpected job: %+v", job)
+	}
+	var payload map[string]string
+	if err := job.Decode(&payload); err != nil || payload["to"] != "a" {
+		t.Errorf("Decode = %v, %v", payload, err)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	db := &fakeDB{affected: 1, rows: [][]any{
+		jobRecord(1, StatusPending, 0, 3, 0),
+		jobRecord(2, StatusRunning, 3, 3, 4), // visibility expired, no attempts left
+	}}
+	q := newTestQueue(db)
+
+	jobs, err := q.claim(context.Background(), "mail", 2, time.Minute)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	want := `SELECT "id","queue","payload","priority","status","attempts","max_attempts","run_at","locked_until","last_error","version","created_date","changed_date"` +
+		` FROM "queue_jobs" WHERE (queue = $1 AND ((status = $2 AND run_at <= $3) OR (status = $4 AND locked_until <= $5)))` +
+		` ORDER BY "priority" DESC, "run_at" ASC, "id" ASC LIMIT $6 FOR UPDATE SKIP LOCKED`
+	if db.sqls[0] != want {
+		t.Errorf("unexpected SQL:\n got: %s\nwant: %s", db.sqls[0], want)
+	}
+	if db.committed != 1 {
+		t.Errorf("expected the claim to commit, got %d commits", db.committed)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 claimed job, got %d", len(jobs))
+	}
+	j := jobs[0]
+	if j.ID != 1 || j.Status != StatusRunning || j.Attempts != 1 || j.Version != 1 || !j.LockedUntil.Equal(testNow.Add(time.Minute)) {
+		t.Errorf("unexpected claimed job: %+v", j)
+	}
+	if string(j.Payload) != `{"to":"a"}` {
+		t.Errorf("unexpected payload: %s", j.Payload)
+	}
+	// One update claims job 1, one dead-letters job 2; both are fenced by
+	// the version that was read.
+	if len(db.sqls) != 3 || !strings.Contains(db.sqls[1], `"version" = "version" + 1`) || !strings.HasSuffix(db.sqls[2], `AND "version" = $6`) {
+		t.Errorf("unexpected updates: %q", db.sqls[1:])
+	}
+	if args := db.args[2]; !containsArg(args, StatusDead) || !containsArg(args, int64(4)) {
+		t.Errorf("expected job 2 dead-lettered at version 4, got %v", args)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		status   string
+	}{
+		{"success", 1, nil, StatusCompleted},
+		{"retry", 2, errors.New("smtp down"), StatusPending},
+		{"dead", 3, errors.New("smtp down"), StatusDead},
+	}
+	for _, tt := range tests {
+		db := &fakeDB{affected: 1}
+		q := newTestQueue(db, Backoff(time.Second, time.Minute))
+		j := &Job{ID: 7, Status: StatusRunning, Attempts: tt.attempts, MaxAttempts: 3, Version: 2}
+		if err := q.complete(context.Background(), j, tt.err); err != nil {
+			t.Fatalf("%s: complete: %v", tt.name, err)
+		}
+		if j.Status != tt.status || j.Version != 3 || !containsArg(db.args[0], tt.status) {
+			t.Errorf("%s: got job %+v, args %v", tt.name, j, db.args[0])
+		}
+		if tt.err != nil && (j.LastError == nil || *j.LastError != "smtp down") {
+			t.Errorf("%s: expected last error recorded, got %v", tt.name, j.LastError)
+		}
+		if tt.status == StatusPending {
+			// Attempt 2 backs off 2s plus up to 1s of jitter.
+			if d := j.RunAt.Sub(testNow); d < 2*time.Second || d >= 3*time.Second {
+				t.Errorf("%s: unexpected backoff %v", tt.name, d)
+			}
+		}
+	}
+
+	db := &fakeDB{affected: 0}
+	j := &Job{ID: 7, Status: StatusRunning, Attempts: 1, MaxAttempts: 3, Version: 2}
+	if err := newTestQueue(db).complete(context.Background(), j, nil); !errors.Is(err, curd.ErrStaleObject) {
+		t.Errorf("expected ErrStaleObject for a reclaimed job, got %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	db := &fakeDB{affected: 1, rows: [][]any{jobRecord(7, StatusDead, 3, 3, 5)}}
+	if err := newTestQueue(db).Retry(context.Background(), 7); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if len(db.sqls) != 2 || !strings.HasSuffix(db.sqls[0], "FOR UPDATE") || !strings.HasPrefix(db.sqls[1], `UPDATE "queue_jobs"`) {
+		t.Errorf("expected a locked read then an update, got %q", db.sqls)
+	}
+	if !containsArg(db.args[1], StatusPending) || db.committed != 1 {
+		t.Errorf("expected job 7 requeued and committed, got %v", db.args[1])
+	}
+
+	db = &fakeDB{affected: 1}
+	if err := newTestQueue(db).Retry(context.Background(), 8); !errors.Is(err, curd.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a job that is not dead, got %v", err)
+	}
+	if len(db.sqls) != 1 {
+		t.Errorf("expected no update, got %q", db.sqls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	q := newTestQueue(&fakeDB{}, Backoff(time.Second, 10*time.Second))
+	for attempt, base := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 9: 10 * time.Second} {
+		if d := q.backoff(attempt); d < base || d >= base+base/2 {
+			t.Errorf("backoff(%d) = %v, want [%v, %v)", attempt, d, base, base+base/2)
+		}
+	}
+}
+
+// ============================================
+// Worker Tests
+// ============================================
+
+func TestHandleRecoversPanic(t *testing.T) {
+	db := &fakeDB{affected: 1}
+	q := newTestQueue(db)
+	r := &route[string]{name: "mail", visibility: time.Minute, q: q, dep: "dep",
+		fn: func(_ context.Context, dep string, job *Job) error {
+			if dep != "dep" {
+				t.Errorf("unexpected dep %q", dep)
+			}
+			panic("boom")
+		}}
+	j := &Job{ID: 1, Status: StatusRunning, Attempts: 1, MaxAttempts: 3}
+	r.handle(context.Background(), j)
+	if j.Status != StatusPending || j.LastError == nil || *j.LastError != "panic: boom" {
+		t.Errorf("expected a retry after the panic, got %+v", j)
+	}
+}
+
+func TestHandleExtendsVisibility(t *testing.T) {
+	db := &fakeDB{affected: 1}
+	q := newTestQueue(db)
+	r := &route[struct{}]{name: "mail", visibility: 30 * time.Millisecond, q: q,
+		fn: func(ctx context.Context, _ struct{}, _ *Job) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}}
+	j := &Job{ID: 1, Status: StatusRunning, Attempts: 1, MaxAttempts: 3}
+	r.handle(context.Background(), j)
+	if j.Status != StatusCompleted || j.Version < 3 {
+		t.Errorf("expected heartbeats then completion, got %+v", j)
+	}
+}
+
+func TestHandleCancelsReclaimedJob(t *testing.T) {
+	db := &fakeDB{affected: 0} // every fenced update misses
+	q := newTestQueue(db)
+	canceled := make(chan bool, 1)
+	r := &route[struct{}]{name: "mail", visibility: 15 * time.Millisecond, q: q,
+		fn: func(ctx context.Context, _ struct{}, _ *Job) error {
+			select {
+			case <-ctx.Done():
+				canceled <- true
+			case <-time.After(time.Second):
+				canceled <- false
+			}
+			return ctx.Err()
+		}}
+	r.handle(context.Background(), &Job{ID: 1, Status: StatusRunning, Attempts: 1, MaxAttempts: 3})
+	if !<-canceled {
+		t.Error("expected the handler context to be canceled")
+	}
+}
+
+func TestRoutePanicsOnInvalidArgs(t *testing.T) {
+	w := NewWorker(struct{}{}, newTestQueue(&fakeDB{}))
+	for _, tt := range []struct {
+		concurrency int
+		visibility  time.Duration
+	}{{0, time.Second}, {1, 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Route(%d, %v) should panic", tt.concurrency, tt.visibility)
+				}
+			}()
+			w.Route("mail", tt.concurrency, tt.visibility, nil)
+		}()
+	}
+}
+
+func containsArg(args []any, want any) bool {
+	for _, a := range args {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestJobPayloadRoundTrip(t *testing.T) {
+	raw := json.RawMessage(`{"n":1}`)
+	db := &fakeDB{}
+	job, err := newTestQueue(db).Enqueue(context.Background(), "mail", raw)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if string(job.Payload) != `{"n":1}` || !containsArgBytes(db.args[0], raw) {
+		t.Errorf("raw payload should be stored as is, got %s", job.Payload)
+	}
+}
+
+func containsArgBytes(args []any, want []byte) bool {
+	for _, a := range args {
+		if b, ok := a.(json.RawMessage); ok && string(b) == string(want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+// Handler processes a job. Returning an error (or panicking) fails the
+// attempt: the job is retried after a backoff, or dead-lettered when it has
+// no attempts left.
+type Handler[T any] func(ctx context.Context, dep T, job *Job) error
+
+// Worker runs the handlers routed to it, passing each the dependency dep.
+type Worker[T any] struct {
+	dep T
+	q   *Queue
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	started bool
+	pending []*route[T]
+	cancels map[string]context.CancelFunc
+}
+
+// NewWorker creates a Worker claiming jobs from q.
+func NewWorker[T any](dep T, q *Queue) *Worker[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker[T]{
+		dep:     dep,
+		q:       q,
+		ctx:     ctx,
+		cancel:  cancel,
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// Route handles the jobs enqueued under name with fn, running at most
+// concurrency jobs at a time. A claimed job is invisible to other workers
+// for visibility; the Worker extends it every visibility/3 while fn runs,
+// and cancels fn's context if the job was reclaimed meanwhile. Routing a
+// name again replaces its handler.
+func (w *Worker[T]) Route(name string, concurrency int, visibility time.Duration, fn Handler[T]) {
+	if concurrency <= 0 {
+		panic("concurrency must be > 0")
+	}
+	if visibility <= 0 {
+		panic("visibility must be > 0")
+	}
+	r := &route[T]{
+		name:        name,
+		concurrency: concurrency,
+		visibility:  visibility,
+		fn:          fn,
+		dep:         w.dep,
+		q:           w.q,
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if cancel, ok := w.cancels[name]; ok {
+		cancel()
+		delete(w.cancels, name)
+	}
+	if w.started {
+		w.cancels[name] = r.start(w.ctx, &w.wg)
+	} else {
+		w.pending = append(w.pending, r)
+	}
+}
+
+// Start starts claiming jobs for every route.
+func (w *Worker[T]) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.started = true
+	for _, r := range w.pending {
+		w.cancels[r.name] = r.start(w.ctx, &w.wg)
+	}
+	w.pending = nil
+}
+
+// Stop stops claiming jobs and waits for running handlers, whose contexts
+// are canceled, to return.
+func (w *Worker[T]) Stop() {
+	w.cancel()
+	w.wg.Wait()
+	slog.Info("queue worker stopped")
+}
+
+type route[T any] struct {
+	name        string
+	concurrency int
+	visibility  time.Duration
+	fn          Handler[T]
+	dep         T
+	q           *Queue
+}
+
+func (r *route[T]) start(ctx context.Context, wg *sync.WaitGroup) context.CancelFunc {
+	routeCtx, cancel := context.WithCancel(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.run(routeCtx, wg)
+	}()
+	return cancel
+}
+
+// run claims due jobs while handler slots are free, polling when the queue
+// is drained or every slot is busy.
+func (r *route[T]) run(ctx context.Context, wg *sync.WaitGroup) {
+	slots := make(chan struct{}, r.concurrency)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		free := r.concurrency - len(slots)
+		if free == 0 {
+			timer.Reset(r.q.cfg.pollInterval)
+			continue
+		}
+		jobs, err := r.q.claim(ctx, r.name, free, r.visibility)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("claim jobs failed", "queue", r.name, "error", err)
+			}
+			timer.Reset(r.q.cfg.pollInterval)
+			continue
+		}
+		for _, job := range jobs {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				r.handle(ctx, job)
+			}()
+		}
+		// A full batch suggests more due jobs; look again right away.
+		if len(jobs) == free {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.q.cfg.pollInterval)
+		}
+	}
+}
+
+// handle runs the handler for job while keeping its claim alive, then
+// records the outcome.
+func (r *route[T]) handle(ctx context.Context, job *Job) {
+	jobCtx, jobCancel := context.WithCancel(ctx)
+	defer jobCancel()
+
+	done := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		r.heartbeat(jobCtx, jobCancel, job, done)
+	}()
+
+	err := r.call(jobCtx, job)
+	close(done)
+	<-heartbeatDone // job is no longer touched by the heartbeat
+
+	if ctx.Err() != nil && err != nil {
+		// Stopping: leave the job to be reclaimed after its visibility
+		// timeout rather than count the interruption as a failure.
+		return
+	}
+	// The outcome is recorded even when the worker is stopping.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := r.q.complete(recordCtx, job, err); err != nil {
+		if errors.Is(err, curd.ErrStaleObject) {
+			slog.Warn("job was reclaimed before it finished", "queue", r.name, "id", job.ID)
+			return
+		}
+		slog.Error("record job outcome failed", "queue", r.name, "id", job.ID, "error", err)
+	}
+}
+
+// call runs the handler, turning a panic into an error.
+func (r *route[T]) call(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			slog.Error("job panic", "queue", r.name, "id", job.ID, "recover", rec)
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return r.fn(ctx, r.dep, job)
+}
+
+// heartbeat extends the job's visibility until done is closed, canceling
+// the job when the claim cannot be extended.
+func (r *route[T]) heartbeat(ctx context.Context, cancel context.CancelFunc, job *Job, done <-chan struct{}) {
+	ticker := time.NewTicker(max(r.visibility/3, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.q.extend(ctx, job, r.visibility); err != nil && ctx.Err() == nil {
+				slog.Warn("job visibility renewal failed, canceling job", "queue", r.name, "id", job.ID, "error", err)
+				cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package curd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoTxBeginner is returned by Router.Begin when the primary cannot begin
+// transactions.
+var ErrNoTxBeginner = errors.New("curd: primary does not begin transactions")
+
+// ReplicaPolicy chooses the replica that serves a read.
+type ReplicaPolicy int
+
+const (
+	RoundRobin       ReplicaPolicy = iota // each healthy replica in turn
+	LeastConnections                      // the healthy replica with the fewest open Rows
+)
+
+// HealthCheck probes a replica; an error ejects it until a later probe
+// succeeds.
+type HealthCheck func(ctx context.Context, replica Querier) error
+
+// RouterOption configures a Router.
+type RouterOption func(*Router)
+
+// WithReplicaPolicy sets how reads are spread over the replicas. The
+// default is RoundRobin.
+func WithReplicaPolicy(p ReplicaPolicy) RouterOption {
+	return func(r *Router) { r.policy = p }
+}
+
+// WithHealthCheck probes every replica each interval with check (SELECT 1
+// when nil), ejecting the replicas that fail until they pass again. Without
+// it, every replica is always considered healthy.
+func WithHealthCheck(interval time.Duration, check HealthCheck) RouterOption {
+	return func(r *Router) {
+		r.interval = interval
+		r.check = check
+	}
+}
+
+// Router is a Querier that splits reads from writes: Query and QueryRow go
+// to a healthy replica, Exec and transactions to the primary. Reads fall
+// back to the primary when no replica is healthy, and under a context from
+// UsePrimary, e.g. to read a row just written despite replication lag.
+//
+// A Curd on a Router sends all its writes to the primary, including the
+// INSERT ... RETURNING statements it runs with Query or QueryRow. Raw
+// queries that write must be run under UsePrimary. WithTx transactions,
+// and so the locking reads of WithLock, run on the primary too (see
+// WithTx). Bulk copies are not routed; run InsertBatch on a Curd using the
+// primary to load with COPY.
+//
+// Usage:
+//
+//	db := curd.NewRouter(primary, []curd.Querier{replica1, replica2},
+//	    curd.WithReplicaPolicy(curd.LeastConnections),
+//	    curd.WithHealthCheck(5*time.Second, nil),
+//	)
+//	defer db.Close()
+//	users := curd.New[User](db, nil, postgres.Dialect{})
+//	err := users.InsertOne(ctx, u)                    // primary
+//	list, err := users.Find(ctx)                      // a replica
+//	u, err = users.FindByID(curd.UsePrimary(ctx), u.ID) // primary, despite lag
+type Router struct {
+	primary  Querier
+	replicas []*replica
+	policy   ReplicaPolicy
+	next     atomic.Uint64
+
+	interval time.Duration
+	check    HealthCheck
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type replica struct {
+	q       Querier
+	healthy atomic.Bool
+	open    atomic.Int64 // Query results not yet closed
+}
+
+// NewRouter creates a Router over primary and replicas. With
+// WithHealthCheck, it probes the replicas in the background until Close.
+func NewRouter(primary Querier, replicas []Querier, opts ...RouterOption) *Router {
+	r := &Router{primary: primary}
+	for _, opt := range opts {
+		opt(r)
+	}
+	for _, q := range replicas {
+		rep := &replica{q: q}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+	if r.interval > 0 && len(r.replicas) > 0 {
+		if r.check == nil {
+			r.check = pingReplica
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		r.cancel = cancel
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.monitor(ctx)
+		}()
+	}
+	return r
+}
+
+// Close stops the health checks. The primary and replicas are left open.
+func (r *Router) Close() {
+	if r.cancel != nil {
+		r.cancel()
+		r.wg.Wait()
+	}
+}
+
+// primaryKey is the context key of UsePrimary.
+type primaryKey struct{}
+
+// UsePrimary returns a context under which a Router reads from the primary.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func (r *Router) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
+	rep := r.pick(ctx)
+	if rep == nil {
+		return r.primary.Query(ctx, sql, args...)
+	}
+	rep.open.Add(1)
+	rows, err := rep.q.Query(ctx, sql, args...)
+	if err != nil {
+		rep.open.Add(-1)
+		return nil, err
+	}
+	routed := &routedRows{Rows: rows, rep: rep}
+	if cr, ok := rows.(ColumnRows); ok {
+		return &routedColumnRows{routedRows: routed, cr: cr}, nil
+	}
+	return routed, nil
+}
+
+// QueryRow runs on a replica like Query. It is not counted as an open read
+// for LeastConnections: the Row may never be scanned, and holds no cursor
+// once it is.
+func (r *Router) QueryRow(ctx context.Context, sql string, args ...any) Row {
+	rep := r.pick(ctx)
+	if rep == nil {
+		return r.primary.QueryRow(ctx, sql, args...)
+	}
+	return rep.q.QueryRow(ctx, sql, args...)
+}
+
+func (r *Router) Exec(ctx context.Context, sql string, args ...any) (Result, error) {
+	return r.primary.Exec(ctx, sql, args...)
+}
+
+// Begin begins a transaction on the primary.
+func (r *Router) Begin(ctx context.Context) (Tx, error) {
+	b, ok := r.primary.(TxBeginner)
+	if !ok {
+		return nil, ErrNoTxBeginner
+	}
+	return b.Begin(ctx)
+}
+
+// TranslateError translates err with the primary's ErrorTranslator, if any.
+// It implements ErrorTranslator for the standalone functions.
+func (r *Router) TranslateError(err error) error {
+	return translateErr(queryTranslator(r.primary), err)
+}
+
+// pick returns the replica for a read under ctx, or nil for the primary.
+func (r *Router) pick(ctx context.Context) *replica {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+	if v, _ := ctx.Value(primaryKey{}).(bool); v {
+		return nil
+	}
+	// Start from the next replica in turn, so LeastConnections spreads ties.
+	start := int(r.next.Add(1) - 1)
+	var best *replica
+	for i := range r.replicas {
+		rep := r.replicas[(start+i)%len(r.replicas)]
+		if !rep.healthy.Load() {
+			continue
+		}
+		if r.policy != LeastConnections {
+			return rep
+		}
+		if best == nil || rep.open.Load() < best.open.Load() {
+			best = rep
+		}
+	}
+	return best
+}
+
+// monitor probes the replicas every interval until ctx is done.
+func (r *Router) monitor(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkReplicas(ctx)
+		}
+	}
+}
+
+// checkReplicas probes every replica once, concurrently, bounding each
+// probe by the check interval.
+func (r *Router) checkReplicas(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, rep := range r.replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, r.interval)
+			defer cancel()
+			err := r.check(checkCtx, rep.q)
+			if ctx.Err() != nil {
+				return
+			}
+			healthy := err == nil
+			if rep.healthy.Swap(healthy) != healthy {
+				if healthy {
+					slog.Info("replica restored", "replica", i)
+				} else {
+					slog.Warn("replica ejected", "replica", i, "error", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// pingReplica is the default HealthCheck.
+func pingReplica(ctx context.Context, q Querier) error {
+	var one int
+	return q.QueryRow(ctx, "SELECT 1").Scan(&one)
+}
+
+// routedRows releases its replica's open read when closed.
+type routedRows struct {
+	Rows
+	rep  *replica
+	once sync.Once
+}
+
+func (r *routedRows) Close() {
+	r.Rows.Close()
+	r.once.Do(func() { r.rep.open.Add(-1) })
+}
+
+// routedColumnRows is routedRows over Rows that implement ColumnRows.
+type routedColumnRows struct {
+	*routedRows
+	cr ColumnRows
+}
+
+func (r *routedColumnRows) Columns() ([]string, error) { return r.cr.Columns() }
@@ -0,0 +1,178 @@
+// Package sqladapter adapts database/sql handles (*sql.DB, *sql.Tx and
+// *sql.Conn) to curd.Querier and curd.TxBeginner, so Curd[T] works with any
+// database/sql driver.
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+// execQuerier is the subset of methods shared by *sql.DB, *sql.Tx and *sql.Conn.
+type execQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// txStarter is implemented by *sql.DB and *sql.Conn.
+type txStarter interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+type querier struct {
+	q execQuerier
+}
+
+func (q querier) Query(ctx context.Context, query string, args ...any) (curd.Rows, error) {
+	rows, err := q.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &rowsAdapter{Rows: rows}, nil
+}
+
+func (q querier) QueryRow(ctx context.Context, query string, args ...any) curd.Row {
+	return q.q.QueryRowContext(ctx, query, args...)
+}
+
+func (q querier) Exec(ctx context.Context, query string, args ...any) (curd.Result, error) {
+	res, err := q.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &resultAdapter{Result: res}, nil
+}
+
+// TranslateError maps sql.ErrNoRows onto curd.ErrNotFound. It implements
+// curd.ErrorTranslator; driver-specific errors are returned unchanged.
+func (q querier) TranslateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", curd.ErrNotFound, err)
+	}
+	return err
+}
+
+func beginTx(ctx context.Context, s txStarter, opts *sql.TxOptions) (curd.Tx, error) {
+	tx, err := s.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewTx(tx), nil
+}
+
+// DB wraps *sql.DB as a curd.Querier and curd.TxBeginner.
+type DB struct {
+	querier
+	db *sql.DB
+}
+
+// NewDB wraps db. The caller retains ownership and is responsible for closing it.
+func NewDB(db *sql.DB) *DB {
+	return &DB{querier: querier{q: db}, db: db}
+}
+
+// DB returns the underlying *sql.DB, e.g. to share the connection pool with
+// code that works on *sql.Rows directly.
+func (d *DB) DB() *sql.DB { return d.db }
+
+// Begin starts a transaction with the driver's default options.
+func (d *DB) Begin(ctx context.Context) (curd.Tx, error) {
+	return beginTx(ctx, d.db, nil)
+}
+
+// BeginTx starts a transaction with custom options (isolation level, read-only).
+//
+// Usage:
+//
+//	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+//	if err != nil { ... }
+//	defer tx.Rollback(ctx)
+//	txC := c.WithQuerier(tx)
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (curd.Tx, error) {
+	return beginTx(ctx, d.db, opts)
+}
+
+// Close closes the underlying *sql.DB.
+func (d *DB) Close() error { return d.db.Close() }
+
+// Conn wraps a single dedicated *sql.Conn as a curd.Querier and curd.TxBeginner.
+type Conn struct {
+	querier
+	conn *sql.Conn
+}
+
+// NewConn wraps conn. The caller retains ownership and is responsible for closing it.
+func NewConn(conn *sql.Conn) *Conn {
+	return &Conn{querier: querier{q: conn}, conn: conn}
+}
+
+// Conn returns the underlying *sql.Conn.
+func (c *Conn) Conn() *sql.Conn { return c.conn }
+
+// Begin starts a transaction on this connection with the driver's default options.
+func (c *Conn) Begin(ctx context.Context) (curd.Tx, error) {
+	return beginTx(ctx, c.conn, nil)
+}
+
+// BeginTx starts a transaction on this connection with custom options.
+func (c *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (curd.Tx, error) {
+	return beginTx(ctx, c.conn, opts)
+}
+
+// Close returns the connection to the pool.
+func (c *Conn) Close() error { return c.conn.Close() }
+
+// Tx wraps *sql.Tx as a curd.Tx.
+type Tx struct {
+	querier
+	tx *sql.Tx
+}
+
+// NewTx wraps a transaction started elsewhere.
+func NewTx(tx *sql.Tx) *Tx {
+	return &Tx{querier: querier{q: tx}, tx: tx}
+}
+
+// Tx returns the underlying *sql.Tx.
+func (t *Tx) Tx() *sql.Tx { return t.tx }
+
+// Commit commits the transaction. database/sql binds the transaction to the
+// context given to BeginTx, so ctx is not used here.
+func (t *Tx) Commit(context.Context) error { return t.tx.Commit() }
+
+// Rollback aborts the transaction.
+func (t *Tx) Rollback(context.Context) error { return t.tx.Rollback() }
+
+// rowsAdapter adapts *sql.Rows, whose Close returns an error, to curd.Rows.
+type rowsAdapter struct{ *sql.Rows }
+
+func (r *rowsAdapter) Close() { _ = r.Rows.Close() }
+
+// resultAdapter adapts sql.Result to curd.Result and curd.LastInsertIDResult.
+type resultAdapter struct{ sql.Result }
+
+// RowsAffected returns the number of affected rows, or -1 if the driver
+// cannot report it, so that an unknown count is not taken for no rows.
+func (r *resultAdapter) RowsAffected() int64 {
+	n, err := r.Result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+var (
+	_ curd.Querier            = (*DB)(nil)
+	_ curd.TxBeginner         = (*DB)(nil)
+	_ curd.Querier            = (*Conn)(nil)
+	_ curd.TxBeginner         = (*Conn)(nil)
+	_ curd.Tx                 = (*Tx)(nil)
+	_ curd.ErrorTranslator    = (*DB)(nil)
+	_ curd.LastInsertIDResult = (*resultAdapter)(nil)
+	_ curd.ColumnRows         = (*rowsAdapter)(nil)
+)
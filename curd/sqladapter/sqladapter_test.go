@@ -0,0 +1,304 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	curd "github.com/gobkc/do/curd"
+)
+
+// ============================================
+// Fake database/sql driver
+// ============================================
+
+type fakeState struct {
+	mu         sync.Mutex
+	queries    []string
+	committed  int
+	rolledBack int
+	txOpts     []driver.TxOptions
+}
+
+var state = &fakeState{}
+
+func (s *fakeState) log(q string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, q)
+}
+
+func (s *fakeState) last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queries) == 0 {
+		return ""
+	}
+	return s.queries[len(s.queries)-1]
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{query: query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return &fakeTx{}, nil }
+
+func (c *fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	state.mu.Lock()
+	state.txOpts = append(state.txOpts, opts)
+	state.mu.Unlock()
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	state.mu.Lock()
+	state.committed++
+	state.mu.Unlock()
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	state.mu.Lock()
+	state.rolledBack++
+	state.mu.Unlock()
+	return nil
+}
+
+type fakeStmt struct{ query string }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	state.log(s.query)
+	if strings.Contains(s.query, "fail") {
+		return nil, errors.New("exec failed")
+	}
+	if strings.Contains(s.query, "nocount") {
+		return driver.ResultNoRows, nil
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	state.log(s.query)
+	if strings.Contains(s.query, "empty") {
+		return &fakeRows{cols: []string{"id", "name"}}, nil
+	}
+	return &fakeRows{
+		cols: []string{"id", "name"},
+		data: [][]driver.Value{{int64(1), []byte("alice")}, {int64(2), []byte("bob")}},
+	}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	data [][]driver.Value
+	pos  int
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.data) {
+		return io.EOF
+	}
+	copy(dest, r.data[r.pos])
+	r.pos++
+	return nil
+}
+
+func init() {
+	sql.Register("curdfake", fakeDriver{})
+}
+
+type fakeDialect struct{}
+
+func (fakeDialect) Placeholder(int) string        { return "?" }
+func (fakeDialect) QuoteIdent(name string) string { return name }
+func (fakeDialect) SupportsReturning() bool       { return false }
+func (fakeDialect) LimitOffset(limit, offset string) string {
+	if limit == "" {
+		return ""
+	}
+	return " LIMIT " + limit
+}
+func (fakeDialect) OnConflict([]string, []string) string { return "" }
+
+type user struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (user) TableName() string { return "users" }
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("curdfake", "")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// ============================================
+// Tests
+// ============================================
+
+func TestDBFindAll(t *testing.T) {
+	db := NewDB(openDB(t))
+	c := curd.New[user](db, nil, fakeDialect{})
+
+	users, err := c.FindAll(context.Background(), nil, "", 0, 0)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(users))
+	}
+	if users[0].ID != 1 || users[0].Name != "alice" || users[1].Name != "bob" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
+
+func TestDBExecResult(t *testing.T) {
+	db := NewDB(openDB(t))
+
+	res, err := db.Exec(context.Background(), "UPDATE users SET name = ?", "x")
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if res.RowsAffected() != 2 {
+		t.Errorf("expected 2 rows affected, got %d", res.RowsAffected())
+	}
+	if _, ok := res.(curd.LastInsertIDResult); !ok {
+		t.Error("expected result to implement curd.LastInsertIDResult")
+	}
+}
+
+func TestDBExecUnknownRowsAffected(t *testing.T) {
+	db := NewDB(openDB(t))
+
+	res, err := db.Exec(context.Background(), "UPDATE nocount SET name = ?", "x")
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if n := res.RowsAffected(); n != -1 {
+		t.Errorf("expected -1 for an unknown count, got %d", n)
+	}
+}
+
+func TestDBExecError(t *testing.T) {
+	db := NewDB(openDB(t))
+	if _, err := db.Exec(context.Background(), "fail"); err == nil {
+		t.Error("expected exec error")
+	}
+}
+
+func TestDBQueryRow(t *testing.T) {
+	db := NewDB(openDB(t))
+
+	var id int64
+	var name string
+	if err := db.QueryRow(context.Background(), "SELECT id, name FROM users").Scan(&id, &name); err != nil {
+		t.Fatalf("QueryRow: %v", err)
+	}
+	if id != 1 || name != "alice" {
+		t.Errorf("unexpected row: %d %q", id, name)
+	}
+}
+
+func TestDBNoRowsIsNotFound(t *testing.T) {
+	db := NewDB(openDB(t))
+
+	_, err := curd.QueryRowRaw[user](context.Background(), db, "SELECT id, name FROM empty")
+	if !errors.Is(err, curd.ErrNotFound) {
+		t.Fatalf("expected curd.ErrNotFound, got %v", err)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected error to wrap sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestDBWithTxCommit(t *testing.T) {
+	db := NewDB(openDB(t))
+	before := state.committed
+
+	err := curd.WithTx(context.Background(), db, func(ctx context.Context, tx curd.Querier) error {
+		_, err := tx.Exec(ctx, "UPDATE users SET name = ?", "y")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if state.committed != before+1 {
+		t.Error("expected transaction to be committed")
+	}
+}
+
+func TestDBWithTxRollback(t *testing.T) {
+	db := NewDB(openDB(t))
+	before := state.rolledBack
+
+	err := curd.WithTx(context.Background(), db, func(ctx context.Context, tx curd.Querier) error {
+		_, err := tx.Exec(ctx, "fail")
+		return err
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if state.rolledBack != before+1 {
+		t.Error("expected transaction to be rolled back")
+	}
+}
+
+func TestDBBeginTxOptions(t *testing.T) {
+	db := NewDB(openDB(t))
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("BeginTx: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	state.mu.Lock()
+	opts := state.txOpts[len(state.txOpts)-1]
+	state.mu.Unlock()
+	if sql.IsolationLevel(opts.Isolation) != sql.LevelSerializable || !opts.ReadOnly {
+		t.Errorf("unexpected tx options: %+v", opts)
+	}
+}
+
+func TestConnQuery(t *testing.T) {
+	sqlDB := openDB(t)
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn: %v", err)
+	}
+	c := NewConn(conn)
+	defer c.Close()
+
+	users, err := curd.New[user](c, nil, fakeDialect{}).FindAll(context.Background(), nil, "", 1, 0)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(users) != 2 {
+		t.Errorf("expected 2 users, got %d", len(users))
+	}
+	if !strings.HasSuffix(state.last(), "LIMIT ?") {
+		t.Errorf("unexpected SQL: %s", state.last())
+	}
+}
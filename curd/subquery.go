@@ -0,0 +1,62 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+)
+
+// Subquery is a SELECT built from a Curd and FindOptions, for embedding in
+// another query with InQuery or ExistsQuery. Its arguments are bound through
+// the enclosing query's ArgBuilder, so placeholders are numbered in
+// sequence with the outer statement's. Build it with Curd.Subquery.
+type Subquery struct {
+	render func(b *ArgBuilder) string
+}
+
+// Subquery builds a subquery over T's table from the same options as Find;
+// soft-deleted rows are excluded unless WithTrashed is given. Select a
+// single column with WithColumns for InQuery.
+//
+// The options are validated here, so the returned Subquery renders without
+// error.
+//
+// Usage:
+//
+//	vips, err := customers.Subquery(curd.WithColumns("id"), curd.WithWhere(curd.Eq("tier", "vip")))
+//	if err != nil { return err }
+//	list, err := orders.Find(ctx, curd.WithWhere(curd.And(
+//	    curd.Eq("status", "open"),
+//	    curd.InQuery("customer_id", vips),
+//	)))
+//	// SELECT ... FROM orders WHERE (status = $1 AND customer_id IN
+//	//   (SELECT id FROM customers WHERE tier = $2 AND deleted_date IS NULL)) ...
+func (c *Curd[T]) Subquery(opts ...FindOption) (Subquery, error) {
+	cfg := resolveFindConfig(opts)
+	if _, err := c.renderSelect(context.Background(), cfg, newArgBuilder(c.dialect, 1)); err != nil {
+		return Subquery{}, fmt.Errorf("subquery %s: %w", tableName[T](), err)
+	}
+	return Subquery{render: func(b *ArgBuilder) string {
+		query, _ := c.renderSelect(context.Background(), cfg, b)
+		return query
+	}}, nil
+}
+
+// InQuery returns a Predicate for field IN (subquery).
+func InQuery(field string, sub Subquery) Predicate {
+	return func(b *ArgBuilder) string {
+		if sub.render == nil {
+			return "FALSE"
+		}
+		return fmt.Sprintf("%s IN (%s)", field, sub.render(b))
+	}
+}
+
+// ExistsQuery returns a Predicate for EXISTS (subquery).
+func ExistsQuery(sub Subquery) Predicate {
+	return func(b *ArgBuilder) string {
+		if sub.render == nil {
+			return "FALSE"
+		}
+		return "EXISTS (" + sub.render(b) + ")"
+	}
+}
@@ -1,8 +1,11 @@
 package curd
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"reflect"
 )
 
 // FieldTransformer transforms a field value before it is sent to the database.
@@ -77,3 +80,142 @@ func XMLMarshaler(fields ...string) FieldTransformer {
 		return string(data)
 	}
 }
+
+// FieldDecoder is the read-side counterpart of FieldTransformer. It converts
+// a value scanned from the database before it is assigned to a struct field.
+// It receives the column name, the scanned value (never nil) and the type of
+// the destination field, and returns the value to assign. Values it does not
+// handle should be returned unchanged.
+//
+// Decoders are registered with Curd.WithDecoder, or with ContextWithDecoder
+// for QueryRaw, QueryRowRaw and QueryIter (which map by Go field name, so
+// fieldName is the field name there). Multiple decoders can be composed with
+// ComposeDecoders.
+type FieldDecoder func(fieldName string, value any, target reflect.Type) (any, error)
+
+// ComposeDecoders composes multiple FieldDecoders into one.
+// Decoders are applied in order, each receiving the output of the previous.
+// The first error stops the chain.
+//
+// Usage:
+//
+//	d := ComposeDecoders(
+//	    JSONBUnmarshaler("metadata", "config"),
+//	    XMLUnmarshaler("document"),
+//	)
+func ComposeDecoders(decoders ...FieldDecoder) FieldDecoder {
+	return func(fieldName string, value any, target reflect.Type) (any, error) {
+		var err error
+		for _, d := range decoders {
+			if value == nil {
+				return nil, nil
+			}
+			if value, err = d(fieldName, value, target); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+}
+
+// JSONBUnmarshaler returns a FieldDecoder that JSON-unmarshals the specified
+// fields into their Go type, reversing JSONBMarshaler. It accepts the text or
+// bytes of a json/jsonb column as well as values a driver already decoded
+// (e.g. pgx returns map[string]any), which are re-encoded first.
+//
+// Usage:
+//
+//	c.WithTransformer(JSONBMarshaler("metadata")).WithDecoder(JSONBUnmarshaler("metadata"))
+func JSONBUnmarshaler(fields ...string) FieldDecoder {
+	return unmarshalDecoder(fields, json.Marshal, json.Unmarshal)
+}
+
+// XMLUnmarshaler returns a FieldDecoder that XML-unmarshals the specified
+// fields into their Go type, reversing XMLMarshaler.
+//
+// Usage:
+//
+//	c.WithDecoder(XMLUnmarshaler("document"))
+func XMLUnmarshaler(fields ...string) FieldDecoder {
+	return unmarshalDecoder(fields, nil, xml.Unmarshal)
+}
+
+// unmarshalDecoder builds a FieldDecoder for a text encoding. marshal, when
+// non-nil, re-encodes values the driver has already decoded.
+func unmarshalDecoder(fields []string, marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) FieldDecoder {
+	fieldSet := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		fieldSet[f] = true
+	}
+	return func(fieldName string, value any, target reflect.Type) (any, error) {
+		if !fieldSet[fieldName] || value == nil {
+			return value, nil
+		}
+		var data []byte
+		switch v := value.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			if marshal == nil || reflect.TypeOf(value).AssignableTo(target) {
+				return value, nil
+			}
+			var err error
+			if data, err = marshal(value); err != nil {
+				return nil, fmt.Errorf("decode %s: %w", fieldName, err)
+			}
+		}
+		switch target.Kind() {
+		case reflect.String:
+			return string(data), nil
+		case reflect.Slice:
+			if target.Elem().Kind() == reflect.Uint8 {
+				return data, nil
+			}
+		}
+		ptr := reflect.New(target)
+		if err := unmarshal(data, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", fieldName, err)
+		}
+		return ptr.Elem().Interface(), nil
+	}
+}
+
+type decoderCtxKey struct{}
+
+// ContextWithDecoder returns a context that makes QueryRaw, QueryRowRaw and
+// QueryIter apply d to scanned values. Decoders already in ctx run first.
+func ContextWithDecoder(ctx context.Context, d FieldDecoder) context.Context {
+	if prev := decoderFromContext(ctx); prev != nil {
+		d = ComposeDecoders(prev, d)
+	}
+	return context.WithValue(ctx, decoderCtxKey{}, d)
+}
+
+// decoderFromContext returns the FieldDecoder stored by ContextWithDecoder,
+// or nil.
+func decoderFromContext(ctx context.Context) FieldDecoder {
+	d, _ := ctx.Value(decoderCtxKey{}).(FieldDecoder)
+	return d
+}
+
+// decodeTargets runs d over the scanned values in targets, replacing each
+// with the decoded value for its field.
+func decodeTargets(d FieldDecoder, columns []string, fields []reflect.Value, targets []any) error {
+	if d == nil {
+		return nil
+	}
+	for i, f := range fields {
+		ptr, ok := targets[i].(*any)
+		if !ok || ptr == nil || *ptr == nil {
+			continue
+		}
+		val, err := d(columns[i], *ptr, f.Type())
+		if err != nil {
+			return err
+		}
+		*ptr = val
+	}
+	return nil
+}
@@ -2,19 +2,108 @@ package curd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 )
 
 type TxFunc func(ctx context.Context, tx Querier) error
 
 type TxFuncResult[T any] func(ctx context.Context, tx Querier) (T, error)
 
+// txKey is the context key of the transactions opened by WithTx.
+type txKey struct{}
+
+// ctxTx is a transaction carried by a context. parent is the transaction
+// of the enclosing WithTx on another TxBeginner, if any.
+type ctxTx struct {
+	b      TxBeginner
+	tx     Tx
+	depth  int // savepoint nesting depth
+	parent *ctxTx
+}
+
+// lookupTx returns the transaction that ctx carries for q, or nil.
+func lookupTx(ctx context.Context, q any) *ctxTx {
+	v, _ := ctx.Value(txKey{}).(*ctxTx)
+	for ; v != nil; v = v.parent {
+		if sameQuerier(v.b, q) {
+			return v
+		}
+	}
+	return nil
+}
+
+// txRoot returns the TxBeginner that b wraps through Unwrap() TxBeginner
+// methods, or b itself.
+func txRoot(b TxBeginner) TxBeginner {
+	for {
+		u, ok := b.(interface{ Unwrap() TxBeginner })
+		if !ok {
+			return b
+		}
+		b = u.Unwrap()
+	}
+}
+
+// sameQuerier reports whether a and b are the same database handle.
+func sameQuerier(a, b any) bool {
+	t := reflect.TypeOf(a)
+	return t != nil && t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// ContextQuerier returns the transaction that a WithTx on q has stored in
+// ctx, or q itself outside of one. Curd does this for every query, so it is
+// only needed for Queriers used directly, e.g. with QueryRaw:
+//
+//	rows, err := curd.QueryRaw[Row](ctx, curd.ContextQuerier(ctx, pool), sql, args...)
+func ContextQuerier(ctx context.Context, q Querier) Querier {
+	if v := lookupTx(ctx, q); v != nil {
+		return v.tx
+	}
+	return q
+}
+
 // WithTx executes fn within a transaction. If fn returns an error, the transaction
 // is rolled back; otherwise it is committed.
+//
+// The transaction is also stored in the context passed to fn, and every Curd
+// whose Querier is b runs its queries in it, so repositories need not thread
+// tx through:
+//
+//	err := curd.WithTx(ctx, pool, func(ctx context.Context, _ curd.Querier) error {
+//	    if err := orders.InsertOne(ctx, order); err != nil { // orders uses pool
+//	        return err
+//	    }
+//	    return stock.Reserve(ctx, order.Items) // joins the same transaction
+//	})
+//
+// A WithTx on b nested in another opens a SAVEPOINT in the enclosing
+// transaction instead of a new one. An error from fn rolls back to the
+// savepoint and leaves the enclosing transaction usable; on success the
+// savepoint is released and the work commits with the enclosing
+// transaction.
+//
+// A TxBeginner that wraps another, e.g. to set the isolation level, shares
+// the wrapped one's transactions if it has an Unwrap() TxBeginner method.
 func WithTx(ctx context.Context, b TxBeginner, fn TxFunc) error {
+	_, err := WithTxResult(ctx, b, func(ctx context.Context, tx Querier) (struct{}, error) {
+		return struct{}{}, fn(ctx, tx)
+	})
+	return err
+}
+
+// WithTxResult executes fn within a transaction and returns its result.
+// It joins a transaction in ctx like WithTx.
+func WithTxResult[T any](ctx context.Context, b TxBeginner, fn TxFuncResult[T]) (T, error) {
+	root := txRoot(b)
+	if outer := lookupTx(ctx, root); outer != nil {
+		return withSavepoint(ctx, outer, fn)
+	}
+	var result T
 	tx, err := b.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return result, fmt.Errorf("begin tx: %w", err)
 	}
 	defer func() {
 		if r := recover(); r != nil {
@@ -22,36 +111,55 @@ func WithTx(ctx context.Context, b TxBeginner, fn TxFunc) error {
 			panic(r)
 		}
 	}()
-	if err := fn(ctx, tx); err != nil {
+	parent, _ := ctx.Value(txKey{}).(*ctxTx)
+	txCtx := context.WithValue(ctx, txKey{}, &ctxTx{b: root, tx: tx, parent: parent})
+	result, err = fn(txCtx, tx)
+	if err != nil {
 		_ = tx.Rollback(ctx)
-		return err
+		return result, err
 	}
 	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("commit tx: %w", err)
+		return result, fmt.Errorf("commit tx: %w", err)
 	}
-	return nil
+	return result, nil
 }
 
-// WithTxResult executes fn within a transaction and returns its result.
-func WithTxResult[T any](ctx context.Context, b TxBeginner, fn TxFuncResult[T]) (T, error) {
+// withSavepoint runs fn in a savepoint of the transaction outer.
+func withSavepoint[T any](ctx context.Context, outer *ctxTx, fn TxFuncResult[T]) (T, error) {
 	var result T
-	tx, err := b.Begin(ctx)
-	if err != nil {
-		return result, fmt.Errorf("begin tx: %w", err)
+	head, _ := ctx.Value(txKey{}).(*ctxTx)
+	inner := &ctxTx{b: outer.b, tx: outer.tx, depth: outer.depth + 1, parent: head}
+	name := fmt.Sprintf("curd_sp_%d", inner.depth)
+	if _, err := outer.tx.Exec(ctx, "SAVEPOINT "+name); err != nil {
+		return result, fmt.Errorf("savepoint: %w", err)
 	}
 	defer func() {
 		if r := recover(); r != nil {
-			_ = tx.Rollback(ctx)
+			_ = rollbackSavepoint(ctx, outer.tx, name)
 			panic(r)
 		}
 	}()
-	result, err = fn(ctx, tx)
+	result, err := fn(context.WithValue(ctx, txKey{}, inner), outer.tx)
 	if err != nil {
-		_ = tx.Rollback(ctx)
+		if rbErr := rollbackSavepoint(ctx, outer.tx, name); rbErr != nil {
+			return result, errors.Join(err, rbErr)
+		}
 		return result, err
 	}
-	if err := tx.Commit(ctx); err != nil {
-		return result, fmt.Errorf("commit tx: %w", err)
+	if _, err := outer.tx.Exec(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return result, fmt.Errorf("release savepoint: %w", err)
 	}
 	return result, nil
 }
+
+// rollbackSavepoint undoes the work since the savepoint name, then releases
+// it so it doesn't linger until the transaction ends.
+func rollbackSavepoint(ctx context.Context, tx Querier, name string) error {
+	if _, err := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("rollback to savepoint: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
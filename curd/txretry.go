@@ -0,0 +1,113 @@
+package curd
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// TxRetryOptions configures WithTxRetry. Zero fields take their defaults.
+type TxRetryOptions struct {
+	// MaxAttempts is the number of times fn may run, including the first.
+	// Default 3.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles after each
+	// retry, plus up to 50% jitter. Default 10ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before jitter. Default 1s.
+	MaxDelay time.Duration
+	// OnRetry, if set, is called with the failed attempt's number and error
+	// before waiting delay to retry.
+	OnRetry func(ctx context.Context, attempt int, err error, delay time.Duration)
+}
+
+const (
+	defaultTxRetryAttempts = 3
+	defaultTxRetryBase     = 10 * time.Millisecond
+	defaultTxRetryMax      = time.Second
+)
+
+// WithTxRetry runs fn in a transaction like WithTx, and runs the whole
+// transaction again when it fails with ErrSerializationFailure or
+// ErrDeadlock, which the database resolves by aborting one of the
+// conflicting transactions. fn must therefore be safe to run more than
+// once: no side effects outside the transaction.
+//
+// Errors are classified after translation by b when it implements
+// ErrorTranslator, so failures reported by COMMIT are retried too. The last
+// error is returned once MaxAttempts is reached or ctx is done.
+//
+// Nested in a transaction on b, fn runs once in a savepoint (see WithTx):
+// the conflict aborts the enclosing transaction, so only the outermost
+// WithTxRetry can retry it.
+//
+// Usage:
+//
+//	serializable := pool.WithTxOptions(pgx.TxOptions{IsoLevel: pgx.Serializable})
+//	err := curd.WithTxRetry(ctx, serializable, curd.TxRetryOptions{
+//	    MaxAttempts: 5,
+//	    OnRetry: func(ctx context.Context, attempt int, err error, delay time.Duration) {
+//	        slog.Warn("retrying transaction", "attempt", attempt, "error", err)
+//	    },
+//	}, func(ctx context.Context, tx curd.Querier) error {
+//	    return transfer(ctx, from, to, amount)
+//	})
+func WithTxRetry(ctx context.Context, b TxBeginner, opts TxRetryOptions, fn TxFunc) error {
+	_, err := WithTxRetryResult(ctx, b, opts, func(ctx context.Context, tx Querier) (struct{}, error) {
+		return struct{}{}, fn(ctx, tx)
+	})
+	return err
+}
+
+// WithTxRetryResult is WithTxRetry returning fn's result.
+func WithTxRetryResult[T any](ctx context.Context, b TxBeginner, opts TxRetryOptions, fn TxFuncResult[T]) (T, error) {
+	if lookupTx(ctx, txRoot(b)) != nil {
+		return WithTxResult(ctx, b, fn)
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultTxRetryAttempts
+	}
+	delay := opts.BaseDelay
+	if delay <= 0 {
+		delay = defaultTxRetryBase
+	}
+	maxDelay := opts.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultTxRetryMax
+	}
+	tr, _ := b.(ErrorTranslator)
+
+	for attempt := 1; ; attempt++ {
+		result, err := WithTxResult(ctx, b, fn)
+		if err == nil {
+			return result, nil
+		}
+		if attempt == maxAttempts || !retryableTx(translateErr(tr, err)) {
+			return result, err
+		}
+
+		wait := min(delay, maxDelay)
+		if half := int64(wait / 2); half > 0 {
+			wait += time.Duration(rand.Int63n(half))
+		}
+		if opts.OnRetry != nil {
+			opts.OnRetry(ctx, attempt, err, wait)
+		}
+		select {
+		case <-ctx.Done():
+			return result, errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+		if delay < maxDelay {
+			delay *= 2
+		}
+	}
+}
+
+// retryableTx reports whether err aborted a transaction that may succeed
+// when run again.
+func retryableTx(err error) bool {
+	return errors.Is(err, ErrSerializationFailure) || errors.Is(err, ErrDeadlock)
+}
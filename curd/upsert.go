@@ -0,0 +1,244 @@
+package curd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// UpsertOnConflict atomically inserts row or, when it collides with an
+// existing row on conflictCols (a unique key), updates updateCols on that row
+// with the incoming values. It is rendered as
+// INSERT ... ON CONFLICT (...) DO UPDATE SET ... RETURNING id on PostgreSQL
+// and INSERT ... ON DUPLICATE KEY UPDATE ... on MySQL.
+//
+// If updateCols is empty, every inserted column except the conflict
+// columns, the primary key and the CreatedDate column is updated. The
+// version column of a versioned entity is incremented on the existing row
+// instead of overwritten. The generated (or existing, where the dialect
+// reports it) id is set back on the row.
+//
+// Unlike Upsert, this requires a unique constraint on conflictCols but is
+// safe under concurrent writers.
+//
+// Which path the statement takes is only known to the database, so the
+// BeforeInsert and AfterInsert hooks run on both paths and BeforeUpdate is
+// never called. Validate in BeforeInsert what must hold for either.
+//
+// Usage:
+//
+//	err := c.UpsertOnConflict(ctx, row, []string{"email"}, []string{"name", "status"})
+func (c *Curd[T]) UpsertOnConflict(ctx context.Context, row *T, conflictCols, updateCols []string) error {
+	v := reflect.ValueOf(row).Elem()
+	tableName := tableName[T]()
+
+	c.stampCreated(v)
+	if err := beforeInsert(ctx, v); err != nil {
+		return fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+
+	cols, vals := rowValues(v, c.fm, c.transforms...)
+	updateCols = c.upsertColumns(cols, conflictCols, updateCols)
+	if len(updateCols) == 0 {
+		return fmt.Errorf("upsert %s: no columns to update, use InsertIgnore", tableName)
+	}
+
+	query, args := c.insertSQL(tableName, cols, [][]any{vals})
+	query += c.onConflictUpdate(conflictCols, updateCols)
+	if err := c.execInsertReturningID(ctx, v, query, args); err != nil {
+		return fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+	if err := afterInsert(ctx, v); err != nil {
+		return fmt.Errorf("upsert %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// InsertIgnore inserts row unless it collides with an existing row on
+// conflictCols, in which case nothing happens (ON CONFLICT DO NOTHING).
+// It reports whether the row was inserted; when it was, the generated id
+// is set back on the row.
+func (c *Curd[T]) InsertIgnore(ctx context.Context, row *T, conflictCols []string) (bool, error) {
+	v := reflect.ValueOf(row).Elem()
+	tableName := tableName[T]()
+
+	c.stampCreated(v)
+	if err := beforeInsert(ctx, v); err != nil {
+		return false, fmt.Errorf("insert ignore %s: %w", tableName, err)
+	}
+
+	inserted, err := c.insertIgnore(ctx, v, conflictCols)
+	if err != nil {
+		return inserted, fmt.Errorf("insert ignore %s: %w", tableName, err)
+	}
+	if inserted {
+		if err := afterInsert(ctx, v); err != nil {
+			return true, fmt.Errorf("insert ignore %s: %w", tableName, err)
+		}
+	}
+	return inserted, nil
+}
+
+// insertIgnore runs the INSERT ... ON CONFLICT DO NOTHING statement for
+// row value v and reports whether a row was inserted.
+func (c *Curd[T]) insertIgnore(ctx context.Context, v reflect.Value, conflictCols []string) (bool, error) {
+	cols, vals := rowValues(v, c.fm, c.transforms...)
+	query, args := c.insertSQL(tableName[T](), cols, [][]any{vals})
+	query += c.onConflictIgnore(conflictCols)
+
+	pk := c.generatedPK()
+	if pk != nil && c.dialect.SupportsReturning() {
+		// A conflicting row yields no RETURNING row, so Query is used
+		// instead of QueryRow to tell the two outcomes apart. Either way
+		// it is a write, for the primary of a Router.
+		query += " RETURNING " + c.quote(pk.column)
+		ctx = UsePrimary(ctx)
+		defer c.logSQL(ctx, query, args...)()
+		rows, err := c.db(ctx).Query(ctx, query, args...)
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			return false, rows.Err()
+		}
+		if err := rows.Scan(settableField(derefValue(v), pk.index).Addr().Interface()); err != nil {
+			return false, err
+		}
+		return true, rows.Err()
+	}
+
+	defer c.logSQL(ctx, query, args...)()
+	res, err := c.db(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	inserted := res.RowsAffected() > 0
+	if pk != nil && inserted {
+		if err := setLastInsertID(v, pk, res); err != nil {
+			return true, err
+		}
+	}
+	return inserted, nil
+}
+
+// UpsertBatchOnConflict upserts rows with multi-row
+// INSERT ... ON CONFLICT DO UPDATE statements, chunked like InsertBatch to
+// stay under the bind-parameter limit. updateCols defaults as in
+// UpsertOnConflict. Generated ids are not written back. As with
+// UpsertOnConflict, BeforeInsert and AfterInsert run on every row whether
+// it was inserted or updated, and BeforeUpdate is not called.
+//
+// PostgreSQL rejects a statement that would update the same row twice, so
+// rows must not contain duplicates on conflictCols.
+func (c *Curd[T]) UpsertBatchOnConflict(ctx context.Context, rows []T, conflictCols, updateCols []string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tableName := tableName[T]()
+
+	cols, tuples, err := c.batchValues(ctx, rows)
+	if err != nil {
+		return fmt.Errorf("upsert batch %s: %w", tableName, err)
+	}
+	updateCols = c.upsertColumns(cols, conflictCols, updateCols)
+	if len(updateCols) == 0 {
+		return fmt.Errorf("upsert batch %s: no columns to update, use InsertIgnoreBatch", tableName)
+	}
+
+	clause := c.onConflictUpdate(conflictCols, updateCols)
+	for _, chunk := range chunkTuples(tuples, len(cols)) {
+		query, args := c.insertSQL(tableName, cols, chunk)
+		if err := c.execLogged(ctx, query+clause, args); err != nil {
+			return fmt.Errorf("upsert batch %s: %w", tableName, err)
+		}
+	}
+
+	for i := range rows {
+		if err := afterInsert(ctx, reflect.ValueOf(&rows[i]).Elem()); err != nil {
+			return fmt.Errorf("upsert batch %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+// InsertIgnoreBatch inserts rows in chunked multi-row statements, like
+// InsertBatch, skipping rows that collide on conflictCols. It returns the
+// number of rows inserted, or -1 if the driver cannot report it.
+// BeforeInsert runs on every row, but AfterInsert is not called: the
+// statement does not report which of the rows were skipped.
+func (c *Curd[T]) InsertIgnoreBatch(ctx context.Context, rows []T, conflictCols []string) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	tableName := tableName[T]()
+
+	cols, tuples, err := c.batchValues(ctx, rows)
+	if err != nil {
+		return 0, fmt.Errorf("insert ignore batch %s: %w", tableName, err)
+	}
+	clause := c.onConflictIgnore(conflictCols)
+	var inserted int64
+	for _, chunk := range chunkTuples(tuples, len(cols)) {
+		query, args := c.insertSQL(tableName, cols, chunk)
+		query += clause
+		done := c.logSQL(ctx, query, args...)
+		res, err := c.db(ctx).Exec(ctx, query, args...)
+		done()
+		if err != nil {
+			return inserted, fmt.Errorf("insert ignore batch %s: %w", tableName, err)
+		}
+		if n := res.RowsAffected(); n < 0 || inserted < 0 {
+			inserted = -1
+		} else {
+			inserted += n
+		}
+	}
+	return inserted, nil
+}
+
+// upsertColumns returns the columns an upsert overwrites with the incoming
+// values: updateCols or, when the caller does not list them, all inserted
+// columns except the conflict key, the primary key and the creation
+// timestamp. The version column is left out either way; onConflictUpdate
+// increments it instead.
+func (c *Curd[T]) upsertColumns(cols, conflictCols, updateCols []string) []string {
+	isVersion := func(col string) bool { return c.version != nil && col == c.version.column }
+	if len(updateCols) > 0 {
+		return slices.DeleteFunc(slices.Clone(updateCols), isVersion)
+	}
+	pk := c.keyFields()
+	created := c.createdColumn()
+	var update []string
+	for _, col := range cols {
+		if isPKColumn(pk, col) || col == created || isVersion(col) || slices.Contains(conflictCols, col) {
+			continue
+		}
+		update = append(update, col)
+	}
+	return update
+}
+
+// onConflictUpdate renders the dialect's conflict clause updating
+// updateCols. For versioned entities the existing row's version is
+// incremented, as by UpdateWhere, rather than reset to the incoming value.
+func (c *Curd[T]) onConflictUpdate(conflictCols, updateCols []string) string {
+	clause := c.dialect.OnConflict(conflictCols, updateCols)
+	if c.version != nil {
+		col := c.version.column
+		clause += fmt.Sprintf(",%s = %s + 1", c.quote(col), c.quote(tableName[T]()+"."+col))
+	}
+	return clause
+}
+
+// onConflictIgnore renders the dialect's "do nothing" conflict clause. A
+// dialect that cannot render it without a column (MySQL assigns one to
+// itself) is given the primary key when conflictCols is empty.
+func (c *Curd[T]) onConflictIgnore(conflictCols []string) string {
+	clause := c.dialect.OnConflict(conflictCols, nil)
+	if clause == "" && len(conflictCols) == 0 {
+		clause = c.dialect.OnConflict([]string{c.keyFields()[0].column}, nil)
+	}
+	return clause
+}
@@ -0,0 +1,102 @@
+package curd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrStaleObject is returned by version-checked updates that match no row:
+// the row was changed (or deleted) since it was read.
+var ErrStaleObject = errors.New("curd: stale object")
+
+// versionField is the optimistic-locking version column of an entity.
+type versionField struct {
+	index  []int
+	column string
+}
+
+// versionFieldOf returns the integer field of struct type t tagged
+// curd:"version", or nil when t has none.
+//
+// Updates through UpdateWhere, UpdateByID, Save, Upsert and UpdateBatch
+// increment the version column. When the update also carries the version
+// the caller read (a struct row, or the version column in an updates map),
+// the statement only matches rows still at that version and ErrStaleObject
+// is returned when none match.
+func versionFieldOf(t reflect.Type, fm FieldMapper) *versionField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, f := range flatFields(t) {
+		if !hasTagOption(f.StructField, "curd", "version") {
+			continue
+		}
+		col := f.columnName(fm)
+		if col == "" {
+			return nil
+		}
+		return &versionField{index: f.Index, column: col}
+	}
+	return nil
+}
+
+// bumpVersion increments the version field of row value v after a
+// successful version-checked update.
+func (vf *versionField) bumpVersion(v reflect.Value) {
+	f := settableField(derefValue(v), vf.index)
+	switch {
+	case f.CanInt():
+		f.SetInt(f.Int() + 1)
+	case f.CanUint():
+		f.SetUint(f.Uint() + 1)
+	}
+}
+
+// updateRow writes every column of row value v to the rows matching where
+// after running its BeforeUpdate hook, checking and advancing its version
+// when T is versioned.
+func (c *Curd[T]) updateRow(ctx context.Context, where Predicate, v reflect.Value) error {
+	c.stampChanged(v)
+	if err := beforeUpdate(ctx, v); err != nil {
+		return fmt.Errorf("update %s: %w", tableName[T](), err)
+	}
+	updates := structToUpdates(v, c.fm, c.transforms)
+	if err := c.updateWhere(ctx, where, updates); err != nil {
+		return err
+	}
+	if c.version != nil {
+		c.version.bumpVersion(v)
+	}
+	return nil
+}
+
+// UpdateBatch updates each row by primary key with all of its columns, one
+// statement per row. Versioned rows are checked and advanced individually;
+// the first stale row aborts the batch with ErrStaleObject. Run it inside
+// WithTx to make the batch atomic.
+func (c *Curd[T]) UpdateBatch(ctx context.Context, rows []*T) error {
+	tableName := tableName[T]()
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		v := reflect.ValueOf(row).Elem()
+		values, ok := pkValues(v, c.pk)
+		if !ok {
+			return fmt.Errorf("update batch %s: entity has no primary key", tableName)
+		}
+		pred, err := c.pkPredicate(Key(values))
+		if err != nil {
+			return fmt.Errorf("update batch %w", err)
+		}
+		if err := c.updateRow(ctx, pred, v); err != nil {
+			return fmt.Errorf("update batch row %d: %w", i, err)
+		}
+	}
+	return nil
+}